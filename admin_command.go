@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	adminModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/admin"
+	authModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	"github.com/rakibulbanna/go-fiber-postgres/logging"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/utils"
+)
+
+const adminUsage = `Usage: app admin <command> [flags] [user]
+
+<user> is a user ID or email. Flags go before it.
+
+Commands:
+  create           Create a user (--email, --name, --password, --role)
+  reset-password   Set a new password and revoke sessions (--password, generated when omitted)
+  promote          Give a user the admin role
+  demote           Give a user the user role
+  disable          Disable an account and reject its tokens
+  enable           Re-enable a disabled account
+  revoke-sessions  Invalidate all tokens issued to a user
+  list             List users (--q, --role, --status active|disabled|deleted, --limit, --offset)
+
+All commands accept --output table|json. Commands changing a user accept
+--reason, recorded in the audit log with actor ID 0.
+`
+
+// adminCommand implements "app admin ...". Changes to a user go through
+// admin.Service as SystemActor, so they are audited and emit events as in the
+// API.
+func adminCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		os.Exit(2)
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("admin "+command, flag.ExitOnError)
+	output := fs.String("output", "table", "Output format: table or json")
+	email := fs.String("email", "", "Email of the new user (create)")
+	name := fs.String("name", "", "Name of the new user (create)")
+	password := fs.String("password", "", "Password (create, reset-password)")
+	role := fs.String("role", "", "Role: user or admin (create, list)")
+	search := fs.String("q", "", "Match a substring of email or name (list)")
+	status := fs.String("status", "", "Filter by status: active, disabled or deleted (list)")
+	limit := fs.Int("limit", 50, "Maximum users to list (list)")
+	offset := fs.Int("offset", 0, "Users to skip (list)")
+	reason := fs.String("reason", "", "Reason recorded in the audit log")
+
+	switch command {
+	case "create", "reset-password", "promote", "demote", "disable", "enable", "revoke-sessions", "list":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown admin command %q\n\n%s", command, adminUsage)
+		os.Exit(2)
+	}
+
+	cfg := loadConfig(fs, args)
+	if *output != "table" && *output != "json" {
+		fmt.Fprintln(os.Stderr, "--output must be table or json")
+		os.Exit(2)
+	}
+	logger, err := logging.SetupTo(os.Stderr, cfg.LogLevel, "text")
+	if err != nil {
+		logging.Fatal("Error setting up logging", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := storage.NewConnection(ctx, storageConfig(cfg, logger))
+	if err != nil {
+		logging.Fatal("Error connecting to database", err)
+	}
+	defer storage.Close(db)
+
+	service := authModule.NewService(db, cfg.JWTSecret).WithContext(ctx)
+	admin := adminModule.NewService(db, cfg.JWTSecret, cfg.AdminImpersonationTTL, cfg.AdminDeleteGracePeriod).WithContext(ctx)
+	printer := userPrinter{json: *output == "json"}
+
+	if command == "list" {
+		users, _, err := service.ListUsers(&dtos.UserFilter{Search: *search, Role: *role, Status: *status, Limit: *limit, Offset: *offset})
+		exitOnAdminError(err)
+		exitOnAdminError(printer.print(users))
+		return
+	}
+
+	if command == "create" {
+		if *email == "" || *name == "" || *password == "" {
+			fmt.Fprintln(os.Stderr, "create requires --email, --name and --password")
+			os.Exit(2)
+		}
+		user, err := service.CreateUser(&dtos.CreateUserRequest{Email: *email, Name: *name, Password: *password, Role: *role})
+		exitOnAdminError(err)
+		exitOnAdminError(printer.print([]dtos.UserDetailResponse{*user}))
+		return
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "%s requires exactly one user ID or email\n", command)
+		os.Exit(2)
+	}
+	user, err := service.FindUser(fs.Arg(0))
+	exitOnAdminError(err)
+
+	switch command {
+	case "reset-password":
+		newPassword := *password
+		if newPassword == "" {
+			newPassword, err = utils.GeneratePassword()
+			exitOnAdminError(err)
+			fmt.Fprintf(os.Stderr, "Generated password: %s\n", newPassword)
+		}
+		_, err = admin.SetPassword(adminModule.SystemActor, user.ID, newPassword, *reason)
+	case "promote":
+		_, err = admin.SetRole(adminModule.SystemActor, user.ID, models.RoleAdmin, *reason)
+	case "demote":
+		_, err = admin.SetRole(adminModule.SystemActor, user.ID, models.RoleUser, *reason)
+	case "disable":
+		_, err = admin.Suspend(adminModule.SystemActor, user.ID, *reason)
+	case "enable":
+		_, err = admin.Unsuspend(adminModule.SystemActor, user.ID, *reason)
+	case "revoke-sessions":
+		_, err = admin.RevokeSessions(adminModule.SystemActor, user.ID, *reason)
+	}
+	exitOnAdminError(err)
+
+	detail, err := service.UserDetail(user.ID)
+	exitOnAdminError(err)
+	exitOnAdminError(printer.print([]dtos.UserDetailResponse{*detail}))
+}
+
+func exitOnAdminError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+type userPrinter struct {
+	json bool
+}
+
+func (p userPrinter) print(users []dtos.UserDetailResponse) error {
+	if p.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(users)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tSTATUS\tCREATED AT")
+	for _, u := range users {
+		status := "active"
+		switch {
+		case u.DeletedAt != nil:
+			status = "deleted since " + u.DeletedAt.Format(time.RFC3339)
+		case u.Disabled:
+			status = "disabled since " + u.DisabledAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", u.ID, u.Email, u.Name, u.Role, status, u.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
@@ -11,10 +11,7 @@ import (
 
 func main() {
 	// Load all models
-	modelsList := []interface{}{
-		&models.User{},
-		&models.Book{},
-	}
+	modelsList := models.All()
 
 	// Extract schema using Atlas GORM provider
 	provider := gormschema.New("postgres")
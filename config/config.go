@@ -1,43 +1,347 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+// Config is the typed application configuration. Each field is named by its
+// env tag; the same name in lower case is the key in YAML/TOML files, and in
+// lower case with dashes the command line flag (DB_HOST, db_host, --db-host).
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBSSLMode  string
-	JWTSecret  string
-	Port       string
+	// Env defaults to production so that a deployment that does not set it
+	// is held to the production checks of Validate
+	Env string `env:"APP_ENV" default:"production" usage:"Environment: development, staging or production"`
+
+	DBHost     string `env:"DB_HOST" default:"localhost" usage:"Database host"`
+	DBPort     string `env:"DB_PORT" default:"5432" usage:"Database port"`
+	DBUser     string `env:"DB_USER" default:"postgres" usage:"Database user"`
+	DBPassword string `env:"DB_PASSWORD" secret:"true" usage:"Database password"`
+	DBName     string `env:"DB_NAME" default:"postgres" usage:"Database name"`
+	DBSSLMode  string `env:"DB_SSLMODE" default:"disable" usage:"Database SSL mode"`
+	// DatabaseURL replaces the DB_* connection fields above when set
+	DatabaseURL string `env:"DATABASE_URL" secret:"true" usage:"postgres:// connection URL, overrides the DB_* connection settings"`
+
+	// Connection pool and driver
+	DBMaxOpenConns       int           `env:"DB_MAX_OPEN_CONNS" default:"25" usage:"Maximum open database connections, 0 for unlimited"`
+	DBMaxIdleConns       int           `env:"DB_MAX_IDLE_CONNS" default:"10" usage:"Maximum idle database connections"`
+	DBConnMaxLifetime    time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"30m" usage:"Maximum lifetime of a database connection, 0 for no limit"`
+	DBConnMaxIdleTime    time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"5m" usage:"Maximum idle time of a database connection, 0 for no limit"`
+	DBStatementTimeout   time.Duration `env:"DB_STATEMENT_TIMEOUT" default:"0s" usage:"Abort statements running longer than this, 0 to disable"`
+	DBPrepareStatements  bool          `env:"DB_PREPARE_STATEMENTS" default:"true" usage:"Cache prepared statements, disable behind transaction-mode poolers"`
+	DBConnectRetries     int           `env:"DB_CONNECT_RETRIES" default:"5" usage:"Retries of the initial database connection"`
+	DBConnectBackoff     time.Duration `env:"DB_CONNECT_BACKOFF" default:"1s" usage:"Initial wait between connection retries, doubled each time"`
+	DBSlowQueryThreshold time.Duration `env:"DB_SLOW_QUERY_THRESHOLD" default:"200ms" usage:"Log queries slower than this, 0 to disable"`
+
+	// Read replicas for book reads
+	DBReplicaURLs           []string      `env:"DB_REPLICA_URLS" secret:"true" usage:"Comma-separated postgres:// URLs of read replicas"`
+	DBReplicaHealthInterval time.Duration `env:"DB_REPLICA_HEALTH_INTERVAL" default:"10s" usage:"How often to check replica health"`
+	DBReplicaMaxLag         time.Duration `env:"DB_REPLICA_MAX_LAG" default:"0s" usage:"Take replicas lagging further behind out of rotation, 0 to disable"`
+	DBReadYourWritesWindow  time.Duration `env:"DB_READ_YOUR_WRITES_WINDOW" default:"5s" usage:"Read from the primary for this long after a user writes"`
+
+	JWTSecret string `env:"JWT_SECRET" default:"your-secret-key-change-in-production" secret:"true" usage:"Secret used to sign JWTs"`
+	Port      string `env:"PORT" default:"8080" usage:"HTTP port"`
+	GRPCPort  string `env:"GRPC_PORT" default:"9090" usage:"gRPC port, empty to disable the gRPC server"`
+	LogLevel  string `env:"LOG_LEVEL" default:"info" usage:"Log level: debug, info, warn or error"`
+	LogFormat string `env:"LOG_FORMAT" default:"json" usage:"Log format: json or text"`
+
+	// API versioning: v1, under /api and /api/v1, is deprecated in favour of /api/v2
+	APIV1DeprecatedAt   time.Time `env:"API_V1_DEPRECATED_AT" default:"2026-10-19" usage:"Date v1 was deprecated, sent in the Deprecation header"`
+	APIV1SunsetAt       time.Time `env:"API_V1_SUNSET_AT" default:"2027-04-30" usage:"Date v1 will be removed, sent in the Sunset header; empty to omit"`
+	APIV1DeprecationURL string    `env:"API_V1_DEPRECATION_URL" usage:"Migration guide linked from v1 responses; empty to omit"`
+
+	LoanOverdueCheckInterval time.Duration `env:"LOAN_OVERDUE_CHECK_INTERVAL" default:"1h" usage:"How often to look for overdue loans"`
+
+	// Webhook deliveries
+	WebhookTimeout          time.Duration `env:"WEBHOOK_TIMEOUT" default:"10s" usage:"Maximum time to wait for a webhook to respond"`
+	WebhookMaxAttempts      int           `env:"WEBHOOK_MAX_ATTEMPTS" default:"8" usage:"Attempts before a webhook delivery is dead-lettered"`
+	WebhookRetryBackoff     time.Duration `env:"WEBHOOK_RETRY_BACKOFF" default:"30s" usage:"Wait before retrying a failed webhook delivery, doubled each time"`
+	WebhookDeliveryInterval time.Duration `env:"WEBHOOK_DELIVERY_INTERVAL" default:"5s" usage:"How often to look for due webhook deliveries"`
+
+	// Outbox relay of book and user events to the bus, webhooks and a broker
+	OutboxRelayInterval time.Duration `env:"OUTBOX_RELAY_INTERVAL" default:"1s" usage:"How often to relay recorded events"`
+	OutboxRetention     time.Duration `env:"OUTBOX_RETENTION" default:"168h" usage:"Keep relayed events this long, 0 to keep them forever"`
+	OutboxMaxAttempts   int           `env:"OUTBOX_MAX_ATTEMPTS" default:"20" usage:"Attempts before an outbox event is dead-lettered"`
+	OutboxBroker        string        `env:"OUTBOX_BROKER" default:"none" usage:"Message broker stand-in events are also relayed to: stdout, file or none"`
+	OutboxBrokerFile    string        `env:"OUTBOX_BROKER_FILE" default:"events.jsonl" usage:"Output file for the file broker"`
+
+	// Admin user moderation
+	AdminImpersonationTTL  time.Duration `env:"ADMIN_IMPERSONATION_TTL" default:"1h" usage:"Lifetime of impersonation tokens issued to admins"`
+	AdminDeleteGracePeriod time.Duration `env:"ADMIN_DELETE_GRACE_PERIOD" default:"720h" usage:"How long a user stays soft-deleted before it can be purged"`
+
+	// Apply pending migrations before serving, see `app migrate`
+	MigrateOnStart   bool `env:"MIGRATE_ON_START" default:"false" usage:"Apply pending migrations on startup"`
+	SchemaDriftCheck bool `env:"SCHEMA_DRIFT_CHECK" default:"true" usage:"Warn on startup when the database schema differs from the models"`
+
+	// How long to keep failing readiness before draining, and how long to wait for in-flight requests
+	ShutdownDelay   time.Duration `env:"SHUTDOWN_DELAY" default:"0s" usage:"Time to fail readiness before draining"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s" usage:"Maximum time to drain in-flight requests"`
+
+	// Tracing
+	ServiceName        string  `env:"OTEL_SERVICE_NAME" default:"go-fiber-api" usage:"Service name reported on spans"`
+	TracingExporter    string  `env:"OTEL_TRACES_EXPORTER" default:"none" usage:"Trace exporter: otlp, stdout, file or none"`
+	TracingFile        string  `env:"OTEL_TRACES_FILE" default:"traces.json" usage:"Output file for the file trace exporter"`
+	TracingSampleRatio float64 `env:"OTEL_TRACES_SAMPLER_ARG" default:"1.0" usage:"Fraction of new traces to sample"`
+}
+
+const defaultJWTSecret = "your-secret-key-change-in-production"
+
+// IsDevelopment reports whether insecure defaults are acceptable.
+func (c *Config) IsDevelopment() bool {
+	return c.Env == "development" || c.Env == "dev"
+}
+
+// Load builds the configuration from, in increasing order of precedence:
+// field defaults, a YAML or TOML file (--config or CONFIG_FILE), environment
+// variables (including those from --env-file or ENV_FILE, and NAME_FILE
+// indirection for secrets) and command line flags. The flags are registered
+// on fs, so callers can add their own before calling Load.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML configuration file")
+	envFile := fs.String("env-file", getEnv("ENV_FILE", ".env.dev"), "Dotenv file loaded into the environment")
+
+	fields := configFields()
+	flagValues := make(map[string]func() string, len(fields))
+	for _, f := range fields {
+		// Bool flags may be given without a value, e.g. --migrate-on-start
+		if f.isBool {
+			value := fs.Bool(f.flag, false, f.usage)
+			flagValues[f.flag] = func() string { return strconv.FormatBool(*value) }
+			continue
+		}
+		value := fs.String(f.flag, "", f.usage)
+		flagValues[f.flag] = func() string { return *value }
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Values already in the environment win over the dotenv file
+	if err := godotenv.Load(*envFile); err != nil {
+		slog.Warn("Could not load env file", "file", *envFile, "error", err)
+	}
+
+	cfg := &Config{}
+	v := reflect.ValueOf(cfg).Elem()
+
+	for _, f := range fields {
+		if err := setField(v.Field(f.index), f.def); err != nil {
+			return nil, fmt.Errorf("invalid default for %s: %w", f.env, err)
+		}
+	}
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			if value, ok := values[f.key]; ok {
+				if err := setField(v.Field(f.index), fileValue(value)); err != nil {
+					return nil, fmt.Errorf("invalid %s in %s: %w", f.key, *configFile, err)
+				}
+			}
+		}
+	}
+
+	for _, f := range fields {
+		value, ok, err := lookupEnv(f.env)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if err := setField(v.Field(f.index), value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", f.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(fl *flag.Flag) {
+		value, ok := flagValues[fl.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+		for _, f := range fields {
+			if f.flag == fl.Name {
+				if err := setField(v.Field(f.index), value()); err != nil {
+					flagErr = fmt.Errorf("invalid --%s: %w", f.flag, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	return cfg, nil
 }
 
-func LoadConfig(envFile string) *Config {
-	if envFile == "" {
-		envFile = ".env"
+type field struct {
+	index  int
+	env    string
+	key    string
+	flag   string
+	def    string
+	usage  string
+	secret bool
+	isBool bool
+}
+
+func configFields() []field {
+	t := reflect.TypeOf(Config{})
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		env := sf.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		fields = append(fields, field{
+			index:  i,
+			env:    env,
+			key:    strings.ToLower(env),
+			flag:   strings.ReplaceAll(strings.ToLower(env), "_", "-"),
+			def:    sf.Tag.Get("default"),
+			usage:  sf.Tag.Get("usage"),
+			secret: sf.Tag.Get("secret") == "true",
+			isBool: sf.Type.Kind() == reflect.Bool,
+		})
 	}
+	return fields
+}
+
+// lookupEnv reads key from the environment, falling back to the contents of
+// the file named by key_FILE so secrets can be mounted instead of exported.
+func lookupEnv(key string) (string, bool, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, true, nil
+	}
+
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %w", key, err)
+	}
+	return strings.TrimSpace(string(content)), true, nil
+}
 
-	err := godotenv.Load(envFile)
+func readFile(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
 	if err != nil {
-		log.Printf("Warning: Error loading %s file: %v", envFile, err)
+		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	return &Config{
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", ""),
-		DBName:     getEnv("DB_NAME", "postgres"),
-		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
-		JWTSecret:  getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-		Port:       getEnv("PORT", "8080"),
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ".toml":
+		err = toml.Unmarshal(content, &values)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	// Keys are matched case-insensitively
+	normalized := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		normalized[strings.ToLower(key)] = value
+	}
+	return normalized, nil
+}
+
+// fileValue converts a decoded YAML/TOML value to the string form setField
+// parses, joining lists with commas.
+func fileValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+func setField(v reflect.Value, value string) error {
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(value)
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case time.Time:
+		var t time.Time
+		if value != "" {
+			var err error
+			if t, err = parseTime(value); err != nil {
+				return err
+			}
+		}
+		v.Set(reflect.ValueOf(t))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return errors.New("unsupported config field type " + v.Type().String())
+	}
+	return nil
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates, taken as midnight UTC.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
 	}
+	return time.Parse(time.RFC3339, value)
 }
 
 func getEnv(key, defaultValue string) string {
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// load runs Load with args in an environment holding only env, reading no
+// dotenv file.
+func load(t *testing.T, env map[string]string, args ...string) *Config {
+	t.Helper()
+
+	t.Setenv("CONFIG_FILE", "")
+	for _, f := range configFields() {
+		t.Setenv(f.env, "")
+		t.Setenv(f.env+"_FILE", "")
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg, err := Load(fs, append([]string{"--env-file", envFile}, args...))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return cfg
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", "port: 7001\nmigrate_on_start: true\n")
+
+	tests := []struct {
+		name           string
+		env            map[string]string
+		args           []string
+		wantPort       string
+		wantMigrate    bool
+		wantDriftCheck bool
+	}{
+		{name: "defaults", wantPort: "8080", wantDriftCheck: true},
+		{name: "file over default", args: []string{"--config", file}, wantPort: "7001", wantMigrate: true, wantDriftCheck: true},
+		{name: "file from environment", env: map[string]string{"CONFIG_FILE": file}, wantPort: "7001", wantMigrate: true, wantDriftCheck: true},
+		{
+			name:     "env over file",
+			env:      map[string]string{"PORT": "7002", "MIGRATE_ON_START": "false"},
+			args:     []string{"--config", file},
+			wantPort: "7002", wantDriftCheck: true,
+		},
+		{
+			name:     "flag over env",
+			env:      map[string]string{"PORT": "7002", "SCHEMA_DRIFT_CHECK": "true"},
+			args:     []string{"--config", file, "--port", "7003", "--schema-drift-check=false"},
+			wantPort: "7003", wantMigrate: true,
+		},
+		{name: "bool flag without value", args: []string{"--migrate-on-start"}, wantPort: "8080", wantMigrate: true, wantDriftCheck: true},
+		{
+			name:     "bool flag set false",
+			env:      map[string]string{"MIGRATE_ON_START": "true"},
+			args:     []string{"--migrate-on-start=false"},
+			wantPort: "8080", wantDriftCheck: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := load(t, tt.env, tt.args...)
+			if cfg.Port != tt.wantPort || cfg.MigrateOnStart != tt.wantMigrate || cfg.SchemaDriftCheck != tt.wantDriftCheck {
+				t.Errorf("port %s, migrate on start %v, drift check %v; want %s, %v, %v",
+					cfg.Port, cfg.MigrateOnStart, cfg.SchemaDriftCheck, tt.wantPort, tt.wantMigrate, tt.wantDriftCheck)
+			}
+		})
+	}
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	secret := writeFile(t, "jwt", "from-file\n")
+	cfg := load(t, map[string]string{"JWT_SECRET_FILE": secret})
+	if cfg.JWTSecret != "from-file" {
+		t.Errorf("JWT secret %q, want %q", cfg.JWTSecret, "from-file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := strings.Repeat("s", 32)
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		// wantErrs are substrings of the error, none for a valid config
+		wantErrs []string
+	}{
+		{
+			name:     "production by default",
+			wantErrs: []string{"JWT_SECRET must be changed", "DB_PASSWORD must be set"},
+		},
+		{name: "development defaults", env: map[string]string{"APP_ENV": "development"}},
+		{name: "production with secrets", env: map[string]string{"JWT_SECRET": secret, "DB_PASSWORD": "pw"}},
+		{name: "production with database URL", env: map[string]string{"JWT_SECRET": secret, "DATABASE_URL": "postgres://u:p@db/app"}},
+		{
+			name:     "short JWT secret",
+			env:      map[string]string{"APP_ENV": "staging", "JWT_SECRET": "short", "DB_PASSWORD": "pw"},
+			wantErrs: []string{"JWT_SECRET must be at least 32 characters"},
+		},
+		{
+			name:     "unknown environment",
+			env:      map[string]string{"APP_ENV": "prod", "JWT_SECRET": secret, "DB_PASSWORD": "pw"},
+			wantErrs: []string{"APP_ENV must be"},
+		},
+		{
+			name:     "ports",
+			env:      map[string]string{"APP_ENV": "development", "PORT": "70000", "GRPC_PORT": "x", "DB_PORT": "0"},
+			wantErrs: []string{"PORT must be a number", "GRPC_PORT must be empty or a number", "DB_PORT must be a number"},
+		},
+		{
+			name:     "same HTTP and gRPC port",
+			env:      map[string]string{"APP_ENV": "development", "PORT": "9000", "GRPC_PORT": "9000"},
+			wantErrs: []string{"GRPC_PORT must differ from PORT"},
+		},
+		{
+			name:     "idle connections over open ones",
+			env:      map[string]string{"APP_ENV": "development", "DB_MAX_OPEN_CONNS": "5", "DB_MAX_IDLE_CONNS": "10"},
+			wantErrs: []string{"DB_MAX_IDLE_CONNS (10) must not exceed DB_MAX_OPEN_CONNS (5)"},
+		},
+		{
+			name:     "database URL scheme",
+			env:      map[string]string{"APP_ENV": "development", "DATABASE_URL": "mysql://db/app"},
+			wantErrs: []string{"DATABASE_URL must be"},
+		},
+		{
+			name:     "sunset before deprecation",
+			env:      map[string]string{"APP_ENV": "development", "API_V1_DEPRECATED_AT": "2027-01-01", "API_V1_SUNSET_AT": "2026-01-01"},
+			wantErrs: []string{"API_V1_SUNSET_AT must not be before API_V1_DEPRECATED_AT"},
+		},
+		{
+			name:     "enumerations",
+			env:      map[string]string{"APP_ENV": "development", "LOG_LEVEL": "trace", "LOG_FORMAT": "xml", "OTEL_TRACES_EXPORTER": "jaeger", "OUTBOX_BROKER": "kafka"},
+			wantErrs: []string{"LOG_LEVEL must be", "LOG_FORMAT must be", "OTEL_TRACES_EXPORTER must be", "OUTBOX_BROKER must be"},
+		},
+		{
+			name:     "webhook attempts",
+			env:      map[string]string{"APP_ENV": "development", "WEBHOOK_MAX_ATTEMPTS": "0"},
+			wantErrs: []string{"WEBHOOK_MAX_ATTEMPTS must be at least 1"},
+		},
+		{
+			name:     "outbox attempts",
+			env:      map[string]string{"APP_ENV": "development", "OUTBOX_MAX_ATTEMPTS": "0"},
+			wantErrs: []string{"OUTBOX_MAX_ATTEMPTS must be at least 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := load(t, tt.env).Validate()
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate passed, want errors %q", tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
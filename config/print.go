@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print writes the effective configuration to w as YAML, in the format Load
+// accepts. Secrets are masked when redacted is set.
+func (c *Config) Print(w io.Writer, redacted bool) error {
+	v := reflect.ValueOf(c).Elem()
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, f := range configFields() {
+		value := fieldString(v.Field(f.index))
+		if redacted && f.secret && value != "" {
+			value = "[REDACTED]"
+		}
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: f.key},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: scalarStyle(value)},
+		)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func fieldString(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case []string:
+		return strings.Join(value, ",")
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// scalarStyle quotes empty strings so they round-trip as strings, not null.
+func scalarStyle(value string) yaml.Style {
+	if value == "" {
+		return yaml.DoubleQuotedStyle
+	}
+	return 0
+}
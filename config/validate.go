@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the configuration for values that would fail at runtime and,
+// outside development, for insecure defaults.
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Env {
+	case "development", "dev", "staging", "production":
+	default:
+		errs = append(errs, fmt.Errorf("APP_ENV must be development, staging or production, got %q", c.Env))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", c.Port))
+	}
+	if c.GRPCPort != "" {
+		if port, err := strconv.Atoi(c.GRPCPort); err != nil || port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("GRPC_PORT must be empty or a number between 1 and 65535, got %q", c.GRPCPort))
+		} else if c.GRPCPort == c.Port {
+			errs = append(errs, errors.New("GRPC_PORT must differ from PORT"))
+		}
+	}
+	if port, err := strconv.Atoi(c.DBPort); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("DB_PORT must be a number between 1 and 65535, got %q", c.DBPort))
+	}
+
+	if c.DatabaseURL != "" {
+		if u, err := url.Parse(c.DatabaseURL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			errs = append(errs, errors.New("DATABASE_URL must be a postgres:// or postgresql:// URL"))
+		}
+	}
+	for _, replicaURL := range c.DBReplicaURLs {
+		if u, err := url.Parse(replicaURL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			errs = append(errs, errors.New("DB_REPLICA_URLS must be postgres:// or postgresql:// URLs"))
+			break
+		}
+	}
+	if len(c.DBReplicaURLs) > 0 && c.DBReplicaHealthInterval <= 0 {
+		errs = append(errs, errors.New("DB_REPLICA_HEALTH_INTERVAL must be positive"))
+	}
+	if c.DBReplicaMaxLag < 0 || c.DBReadYourWritesWindow < 0 {
+		errs = append(errs, errors.New("DB_REPLICA_MAX_LAG and DB_READ_YOUR_WRITES_WINDOW must not be negative"))
+	}
+	if c.DBMaxOpenConns < 0 || c.DBMaxIdleConns < 0 || c.DBConnectRetries < 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS and DB_CONNECT_RETRIES must not be negative"))
+	}
+	if c.DBMaxOpenConns > 0 && c.DBMaxIdleConns > c.DBMaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", c.DBMaxIdleConns, c.DBMaxOpenConns))
+	}
+	if c.DBConnMaxLifetime < 0 || c.DBConnMaxIdleTime < 0 || c.DBStatementTimeout < 0 || c.DBSlowQueryThreshold < 0 {
+		errs = append(errs, errors.New("database durations must not be negative"))
+	}
+	if c.DBConnectRetries > 0 && c.DBConnectBackoff <= 0 {
+		errs = append(errs, errors.New("DB_CONNECT_BACKOFF must be positive when DB_CONNECT_RETRIES is set"))
+	}
+
+	if !c.APIV1SunsetAt.IsZero() && c.APIV1SunsetAt.Before(c.APIV1DeprecatedAt) {
+		errs = append(errs, errors.New("API_V1_SUNSET_AT must not be before API_V1_DEPRECATED_AT"))
+	}
+	if c.APIV1DeprecationURL != "" {
+		if u, err := url.Parse(c.APIV1DeprecationURL); err != nil || u.Scheme == "" {
+			errs = append(errs, errors.New("API_V1_DEPRECATION_URL must be an absolute URL"))
+		}
+	}
+
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	switch strings.ToLower(c.LogFormat) {
+	case "json", "text":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be json or text, got %q", c.LogFormat))
+	}
+
+	switch c.TracingExporter {
+	case "otlp", "stdout", "file", "none":
+	default:
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_EXPORTER must be otlp, stdout, file or none, got %q", c.TracingExporter))
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1, got %v", c.TracingSampleRatio))
+	}
+
+	if c.LoanOverdueCheckInterval <= 0 {
+		errs = append(errs, errors.New("LOAN_OVERDUE_CHECK_INTERVAL must be positive"))
+	}
+	if c.WebhookTimeout <= 0 || c.WebhookRetryBackoff <= 0 || c.WebhookDeliveryInterval <= 0 {
+		errs = append(errs, errors.New("WEBHOOK_TIMEOUT, WEBHOOK_RETRY_BACKOFF and WEBHOOK_DELIVERY_INTERVAL must be positive"))
+	}
+	if c.WebhookMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1, got %d", c.WebhookMaxAttempts))
+	}
+	if c.OutboxRelayInterval <= 0 || c.OutboxRetention < 0 {
+		errs = append(errs, errors.New("OUTBOX_RELAY_INTERVAL must be positive and OUTBOX_RETENTION must not be negative"))
+	}
+	if c.OutboxMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("OUTBOX_MAX_ATTEMPTS must be at least 1, got %d", c.OutboxMaxAttempts))
+	}
+	switch c.OutboxBroker {
+	case "stdout", "file", "none":
+	default:
+		errs = append(errs, fmt.Errorf("OUTBOX_BROKER must be stdout, file or none, got %q", c.OutboxBroker))
+	}
+	if c.ShutdownDelay < 0 || c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_DELAY must not be negative and SHUTDOWN_TIMEOUT must be positive"))
+	}
+
+	if !c.IsDevelopment() {
+		if c.JWTSecret == defaultJWTSecret {
+			errs = append(errs, fmt.Errorf("JWT_SECRET must be changed from its default outside development"))
+		} else if len(c.JWTSecret) < 32 {
+			errs = append(errs, fmt.Errorf("JWT_SECRET must be at least 32 characters outside development"))
+		}
+		if c.DBPassword == "" && c.DatabaseURL == "" {
+			errs = append(errs, fmt.Errorf("DB_PASSWORD must be set outside development"))
+		}
+		if c.DBSSLMode == "disable" && c.DatabaseURL == "" {
+			slog.Warn("Database connections are not encrypted", "env", c.Env, "db_sslmode", c.DBSSLMode)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rakibulbanna/go-fiber-postgres/config"
+	"github.com/rakibulbanna/go-fiber-postgres/logging"
+)
+
+// configCommand implements "app config print [--redacted]".
+func configCommand(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "Usage: app config print [--redacted] [config flags]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	redacted := fs.Bool("redacted", false, "Mask secrets in the output")
+
+	cfg, err := config.Load(fs, args[1:])
+	if err != nil {
+		logging.Fatal("Error loading configuration", err)
+	}
+	if err := cfg.Print(os.Stdout, *redacted); err != nil {
+		logging.Fatal("Error printing configuration", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "\nconfiguration is invalid:\n%v\n", err)
+		os.Exit(1)
+	}
+}
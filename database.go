@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/rakibulbanna/go-fiber-postgres/config"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/tracing"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// storageConfig maps the application configuration to the storage package.
+func storageConfig(cfg *config.Config, logger *slog.Logger) storage.Config {
+	return storage.Config{
+		URL:      cfg.DatabaseURL,
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPort,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  cfg.DBSSLMode,
+		Logger: gormlogger.NewSlogLogger(logger, gormlogger.Config{
+			SlowThreshold:             cfg.DBSlowQueryThreshold,
+			LogLevel:                  gormlogger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+		MaxOpenConns:      cfg.DBMaxOpenConns,
+		MaxIdleConns:      cfg.DBMaxIdleConns,
+		ConnMaxLifetime:   cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime:   cfg.DBConnMaxIdleTime,
+		StatementTimeout:  cfg.DBStatementTimeout,
+		PrepareStatements: cfg.DBPrepareStatements,
+		ConnectRetries:    cfg.DBConnectRetries,
+		ConnectBackoff:    cfg.DBConnectBackoff,
+	}
+}
+
+// openDatabase connects to the primary database with metrics and tracing
+// instrumentation installed.
+func openDatabase(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
+	dbConfig := storageConfig(cfg, logger)
+
+	db, err := storage.NewConnection(ctx, dbConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := metrics.InstrumentDB(db, dbConfig.Database()); err != nil {
+		return nil, err
+	}
+	if err := db.Use(&tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// openReplicas connects to the configured read replicas, instrumented like
+// the primary. Without replicas every read goes to primary.
+func openReplicas(ctx context.Context, cfg *config.Config, logger *slog.Logger, primary *gorm.DB) (*storage.Replicas, error) {
+	dbConfig := storageConfig(cfg, logger)
+
+	replicas, err := storage.NewReplicas(ctx, primary, dbConfig, cfg.DBReplicaURLs, storage.ReplicaOptions{
+		HealthInterval: cfg.DBReplicaHealthInterval,
+		MaxLag:         cfg.DBReplicaMaxLag,
+		StickyWindow:   cfg.DBReadYourWritesWindow,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	i := 0
+	err = replicas.Each(func(name string, db *gorm.DB) error {
+		i++
+		if err := metrics.InstrumentDB(db, fmt.Sprintf("%s_replica_%d", dbConfig.Database(), i)); err != nil {
+			return err
+		}
+		return db.Use(&tracing.GormPlugin{})
+	})
+	if err != nil {
+		replicas.Close()
+		return nil, err
+	}
+	return replicas, nil
+}
@@ -17,7 +17,8 @@ type AuthResponse struct {
 }
 
 type UserResponse struct {
-	ID    uint   `json:"id"`
-	Email string `json:"email"`
+	ID uint `json:"id"`
+	// Email is left out where the caller may not see it
+	Email string `json:"email,omitempty"`
 	Name  string `json:"name"`
 }
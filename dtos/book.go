@@ -15,11 +15,37 @@ type UpdateBookRequest struct {
 }
 
 type BookResponse struct {
-	ID        uint          `json:"id"`
-	UserID    uint          `json:"user_id"`
-	Author    string        `json:"author"`
-	Title     string        `json:"title"`
-	Publisher string        `json:"publisher"`
-	Year      int           `json:"year"`
-	User      *UserResponse `json:"user"`
+	ID            uint          `json:"id"`
+	UserID        uint          `json:"user_id"`
+	Author        string        `json:"author"`
+	Title         string        `json:"title"`
+	Publisher     string        `json:"publisher"`
+	Year          int           `json:"year"`
+	AverageRating float64       `json:"average_rating"`
+	ReviewCount   int           `json:"review_count"`
+	User          *UserResponse `json:"user,omitempty"`
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type ImportBooksResponse struct {
+	DryRun    bool             `json:"dry_run"`
+	Mode      string           `json:"mode"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Imported  int              `json:"imported"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// BookFilter narrows book listings and exports. Zero values are ignored.
+type BookFilter struct {
+	Title     string `query:"title"`
+	Author    string `query:"author"`
+	Publisher string `query:"publisher"`
+	Year      int    `query:"year"`
+	UserID    uint   `query:"user_id"`
 }
@@ -0,0 +1,15 @@
+package dtos
+
+// Envelope is the body of v2 responses: the resource or collection in Data,
+// with optional metadata and related links.
+type Envelope struct {
+	Data    interface{}       `json:"data"`
+	Meta    interface{}       `json:"meta,omitempty"`
+	Links   map[string]string `json:"links,omitempty"`
+	Message string            `json:"message,omitempty"`
+}
+
+// CollectionMeta describes an unpaginated collection.
+type CollectionMeta struct {
+	Count int `json:"count"`
+}
@@ -0,0 +1,45 @@
+package dtos
+
+import "time"
+
+type CreateLoanRequest struct {
+	BookID  uint       `json:"book_id" validate:"required"`
+	Message string     `json:"message"`
+	DueDate *time.Time `json:"due_date"`
+}
+
+type ApproveLoanRequest struct {
+	DueDate *time.Time `json:"due_date"`
+	Note    string     `json:"note"`
+}
+
+type LoanActionRequest struct {
+	Note string `json:"note"`
+}
+
+type LoanEventResponse struct {
+	ID         uint      `json:"id"`
+	ActorID    *uint     `json:"actor_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type LoanResponse struct {
+	ID         uint                `json:"id"`
+	BookID     uint                `json:"book_id"`
+	OwnerID    uint                `json:"owner_id"`
+	BorrowerID uint                `json:"borrower_id"`
+	Status     string              `json:"status"`
+	Message    string              `json:"message"`
+	ApprovedAt *time.Time          `json:"approved_at"`
+	DueDate    *time.Time          `json:"due_date"`
+	ReturnedAt *time.Time          `json:"returned_at"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	Book       *BookResponse       `json:"book,omitempty"`
+	Owner      *UserResponse       `json:"owner,omitempty"`
+	Borrower   *UserResponse       `json:"borrower,omitempty"`
+	Events     []LoanEventResponse `json:"events,omitempty"`
+}
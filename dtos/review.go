@@ -0,0 +1,24 @@
+package dtos
+
+import "time"
+
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Text   string `json:"text"`
+}
+
+type UpdateReviewRequest struct {
+	Rating int    `json:"rating" validate:"omitempty,min=1,max=5"`
+	Text   string `json:"text"`
+}
+
+type ReviewResponse struct {
+	ID        uint          `json:"id"`
+	UserID    uint          `json:"user_id"`
+	BookID    uint          `json:"book_id"`
+	Rating    int           `json:"rating"`
+	Text      string        `json:"text"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	User      *UserResponse `json:"user,omitempty"`
+}
@@ -0,0 +1,57 @@
+package dtos
+
+import "time"
+
+type CreateShelfRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type UpdateShelfRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type AddShelfEntryRequest struct {
+	BookID   uint   `json:"book_id" validate:"required"`
+	Status   string `json:"status" validate:"omitempty,oneof=want_to_read reading read"`
+	Progress int    `json:"progress" validate:"omitempty,min=0,max=100"`
+}
+
+type UpdateShelfEntryRequest struct {
+	Status     string     `json:"status" validate:"omitempty,oneof=want_to_read reading read"`
+	Progress   *int       `json:"progress" validate:"omitempty,min=0,max=100"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+type MoveShelfEntryRequest struct {
+	ShelfID uint `json:"shelf_id" validate:"required"`
+}
+
+type ShelfResponse struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	IsDefault  bool   `json:"is_default"`
+	Status     string `json:"status,omitempty"`
+	EntryCount int64  `json:"entry_count"`
+}
+
+type ShelfEntryResponse struct {
+	ID         uint          `json:"id"`
+	ShelfID    uint          `json:"shelf_id"`
+	BookID     uint          `json:"book_id"`
+	Status     string        `json:"status"`
+	Progress   int           `json:"progress"`
+	StartedAt  *time.Time    `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at"`
+	Book       *BookResponse `json:"book,omitempty"`
+}
+
+type ReadingStatsResponse struct {
+	Year              int       `json:"year"`
+	BooksStarted      int64     `json:"books_started"`
+	BooksFinished     int64     `json:"books_finished"`
+	CurrentlyReading  int64     `json:"currently_reading"`
+	WantToRead        int64     `json:"want_to_read"`
+	AverageDaysToRead float64   `json:"average_days_to_read"`
+	FinishedByMonth   [12]int64 `json:"finished_by_month"`
+}
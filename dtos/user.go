@@ -0,0 +1,69 @@
+package dtos
+
+import "time"
+
+type CreateUserRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=6"`
+	Name     string `json:"name" validate:"required"`
+	Role     string `json:"role"`
+}
+
+// UserDetailResponse is the administrative view of a user.
+type UserDetailResponse struct {
+	ID         uint       `json:"id"`
+	Email      string     `json:"email"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	Disabled   bool       `json:"disabled"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// UserFilter narrows user listings. Zero values are ignored.
+type UserFilter struct {
+	// Search matches a substring of the email or name
+	Search string `query:"q"`
+	Role   string `query:"role"`
+	// Status is "active", "disabled" or "deleted"; deleted users are
+	// otherwise left out
+	Status string `query:"status"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+// PageMeta describes the page of a paginated listing.
+type PageMeta struct {
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// AdminActionRequest carries the justification recorded in the audit log.
+type AdminActionRequest struct {
+	Reason string `json:"reason"`
+}
+
+type PasswordResetResponse struct {
+	// TemporaryPassword is shown once, to be passed on to the user
+	TemporaryPassword string `json:"temporary_password"`
+}
+
+type ImpersonationResponse struct {
+	Token     string             `json:"token"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	User      UserDetailResponse `json:"user"`
+}
+
+type AuditLogResponse struct {
+	ID           uint      `json:"id"`
+	ActorID      uint      `json:"actor_id"`
+	ActorEmail   string    `json:"actor_email"`
+	Action       string    `json:"action"`
+	TargetUserID uint      `json:"target_user_id"`
+	Reason       string    `json:"reason,omitempty"`
+	IP           string    `json:"ip,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
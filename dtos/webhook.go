@@ -0,0 +1,75 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,url"`
+	// Events lists the event types to deliver, all of them when empty
+	Events []string `json:"events"`
+	// Global subscribes to the events of every user, admins only
+	Global bool `json:"global"`
+}
+
+// UpdateWebhookRequest changes the fields that are set. An empty events
+// list subscribes to every event type.
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url" validate:"omitempty,url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+type WebhookResponse struct {
+	ID     uint     `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Global bool     `json:"global"`
+	Active bool     `json:"active"`
+	// Secret signs deliveries, it is only returned on creation
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryFilter narrows delivery listings. Zero values are ignored.
+type WebhookDeliveryFilter struct {
+	// Status is "pending", "succeeded" or "dead"
+	Status string `query:"status"`
+	Event  string `query:"event"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint       `json:"id"`
+	EventID        string     `json:"event_id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// Payload and Log are only included for a single delivery
+	Payload json.RawMessage          `json:"payload,omitempty"`
+	Log     []WebhookAttemptResponse `json:"log,omitempty"`
+}
+
+type WebhookAttemptResponse struct {
+	// StatusCode is 0 when no response was received
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the body of every webhook delivery.
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler consumes an event. Handlers may see an event more than once and
+// should be idempotent; an error has the event handed to every handler
+// again later.
+type Handler func(ctx context.Context, event Event) error
+
+// Bus hands events to in-process subscribers. As an outbox sink, it only
+// carries committed events, in order per aggregate.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds a handler for every event published from now on.
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish calls the handlers in subscription order, stopping at the first
+// error.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handler := range b.handlers {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
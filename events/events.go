@@ -0,0 +1,84 @@
+// Package events describes changes to books and users for consumers outside
+// the request that made them, such as webhooks. Services record events in
+// the transaction of the change through the outbox package, which relays
+// them once committed.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Event types
+const (
+	BookCreated     = "book.created"
+	BookUpdated     = "book.updated"
+	BookDeleted     = "book.deleted"
+	UserCreated     = "user.created"
+	UserSuspended   = "user.suspended"
+	UserUnsuspended = "user.unsuspended"
+	UserDeleted     = "user.deleted"
+	UserRestored    = "user.restored"
+	UserPurged      = "user.purged"
+)
+
+// Types lists every event type.
+var Types = []string{
+	BookCreated, BookUpdated, BookDeleted,
+	UserCreated, UserSuspended, UserUnsuspended, UserDeleted, UserRestored, UserPurged,
+}
+
+// IsValidType reports whether eventType is one of Types.
+func IsValidType(eventType string) bool {
+	for _, t := range Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a change to a book or user.
+type Event struct {
+	// ID is unique per event, for consumers to deduplicate
+	ID   string
+	Type string
+	// AggregateID is the ID of the book or user that changed. Events of
+	// one aggregate are relayed in the order they were recorded.
+	AggregateID uint
+	// UserID is the user the change concerns: the owner of a book, or the
+	// user itself
+	UserID     uint
+	OccurredAt time.Time
+	// Data is the resource after the change, or before it for deletions,
+	// encoded as JSON. Relayed events hold it as a json.RawMessage.
+	Data interface{}
+}
+
+// New returns an event of the given type that happened now.
+func New(eventType string, aggregateID, userID uint, data interface{}) Event {
+	return Event{
+		ID:          newID(),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		UserID:      userID,
+		OccurredAt:  time.Now().UTC(),
+		Data:        data,
+	}
+}
+
+// Aggregate returns the kind of resource the event is about, the prefix of
+// its type: "book" or "user".
+func (e Event) Aggregate() string {
+	aggregate, _, _ := strings.Cut(e.Type, ".")
+	return aggregate
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read does not fail on supported platforms
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	authModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	bookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+	bookapiv1 "github.com/rakibulbanna/go-fiber-postgres/proto/bookapi/v1"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// newGRPCServer serves the services of proto/bookapi/v1 from the same
+// services as the HTTP API, along with the standard health and reflection
+// services. Every service reports NOT_SERVING until the caller marks it
+// serving.
+func newGRPCServer(authMiddleware *middleware.AuthMiddleware, authService *authModule.Service, bookService *bookModule.Service) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(middleware.GRPCRecoverUnary, authMiddleware.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(middleware.GRPCRecoverStream, authMiddleware.StreamInterceptor),
+	)
+	bookapiv1.RegisterBookServiceServer(server, bookModule.NewGRPCServer(bookService))
+	bookapiv1.RegisterAuthServiceServer(server, authModule.NewGRPCServer(authService))
+
+	healthServer := health.NewServer()
+	setGRPCServing(healthServer, false)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return server, healthServer
+}
+
+// setGRPCServing sets the health of the server as a whole, the "" service,
+// and of each API service.
+func setGRPCServing(healthServer *health.Server, serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, service := range []string{"", bookapiv1.BookService_ServiceDesc.ServiceName, bookapiv1.AuthService_ServiceDesc.ServiceName} {
+		healthServer.SetServingStatus(service, status)
+	}
+}
+
+// stopGRPC waits up to timeout for in-flight calls to finish, then cancels
+// the rest.
+func stopGRPC(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		slog.Error("Timed out draining gRPC calls, cancelling them")
+		server.Stop()
+	}
+}
@@ -0,0 +1,213 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+func (c *Controller) ListUsers(ctx *fiber.Ctx) error {
+	filter := dtos.UserFilter{Limit: 50}
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid query parameters",
+		})
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Limit must be between 1 and 100",
+		})
+	}
+	if filter.Offset < 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Offset must not be negative",
+		})
+	}
+
+	users, total, err := c.service.WithContext(ctx.UserContext()).ListUsers(&filter)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": users,
+		"meta": dtos.PageMeta{Total: total, Limit: filter.Limit, Offset: filter.Offset},
+	})
+}
+
+func (c *Controller) GetUser(ctx *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID format",
+		})
+	}
+
+	user, err := c.service.WithContext(ctx.UserContext()).GetUser(uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": user,
+	})
+}
+
+func (c *Controller) SuspendUser(ctx *fiber.Ctx) error {
+	return c.moderate(ctx, "User suspended successfully", (*Service).Suspend)
+}
+
+func (c *Controller) UnsuspendUser(ctx *fiber.Ctx) error {
+	return c.moderate(ctx, "User unsuspended successfully", (*Service).Unsuspend)
+}
+
+func (c *Controller) DeleteUser(ctx *fiber.Ctx) error {
+	return c.moderate(ctx, "User deleted successfully", (*Service).Delete)
+}
+
+func (c *Controller) RestoreUser(ctx *fiber.Ctx) error {
+	return c.moderate(ctx, "User restored successfully", (*Service).Restore)
+}
+
+func (c *Controller) ResetPassword(ctx *fiber.Ctx) error {
+	userID, actor, req, ok := parseAction(ctx)
+	if !ok {
+		return nil
+	}
+
+	password, err := c.service.WithContext(ctx.UserContext()).ResetPassword(actor, userID, req.Reason)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password reset successfully",
+		"data":    dtos.PasswordResetResponse{TemporaryPassword: password},
+	})
+}
+
+func (c *Controller) ImpersonateUser(ctx *fiber.Ctx) error {
+	userID, actor, req, ok := parseAction(ctx)
+	if !ok {
+		return nil
+	}
+
+	impersonation, err := c.service.WithContext(ctx.UserContext()).Impersonate(actor, userID, req.Reason)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Impersonation token issued",
+		"data":    impersonation,
+	})
+}
+
+func (c *Controller) PurgeUser(ctx *fiber.Ctx) error {
+	userID, actor, req, ok := parseAction(ctx)
+	if !ok {
+		return nil
+	}
+
+	if err := c.service.WithContext(ctx.UserContext()).Purge(actor, userID, req.Reason); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "User purged successfully",
+	})
+}
+
+func (c *Controller) GetAuditTrail(ctx *fiber.Ctx) error {
+	userID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID format",
+		})
+	}
+
+	logs, err := c.service.WithContext(ctx.UserContext()).AuditTrail(uint(userID))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": logs,
+	})
+}
+
+func (c *Controller) moderate(ctx *fiber.Ctx, message string, action func(*Service, Actor, uint, string) (*dtos.UserDetailResponse, error)) error {
+	userID, actor, req, ok := parseAction(ctx)
+	if !ok {
+		return nil
+	}
+
+	user, err := action(c.service.WithContext(ctx.UserContext()), actor, userID, req.Reason)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    user,
+	})
+}
+
+// parseAction reads the target user, the acting admin and the optional
+// reason of a moderation request. It reports false once it has written an
+// error response.
+func parseAction(ctx *fiber.Ctx) (uint, Actor, *dtos.AdminActionRequest, bool) {
+	var req dtos.AdminActionRequest
+
+	userID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID format",
+		})
+		return 0, Actor{}, nil, false
+	}
+
+	// The reason is optional, so an empty body is fine
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+			return 0, Actor{}, nil, false
+		}
+	}
+
+	// Get admin ID from context (set by auth middleware)
+	actorID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+		return 0, Actor{}, nil, false
+	}
+
+	return uint(userID), Actor{UserID: actorID, IP: ctx.IP()}, &req, true
+}
@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// Docs describes the routes registered by SetupRoutes for the OpenAPI document.
+func Docs() openapi.Module {
+	action := func(method, path, summary string, response interface{}) openapi.Route {
+		return openapi.Route{Method: method, Path: path, Summary: summary, Auth: openapi.Admin, Request: dtos.AdminActionRequest{}, OptionalBody: true, Response: response}
+	}
+
+	return openapi.Module{
+		Tag:         "Admin",
+		Description: "User moderation, for admins",
+		Routes: []openapi.Route{
+			{Method: http.MethodGet, Path: "/admin/users/", Summary: "Search users", Auth: openapi.Admin, Query: dtos.UserFilter{}, Response: []dtos.UserDetailResponse{}, Meta: dtos.PageMeta{}},
+			{Method: http.MethodGet, Path: "/admin/users/:id", Summary: "Get a user", Auth: openapi.Admin, Response: dtos.UserDetailResponse{}},
+			{Method: http.MethodGet, Path: "/admin/users/:id/audit", Summary: "Admin actions taken on a user", Auth: openapi.Admin, Response: []dtos.AuditLogResponse{}},
+			action(http.MethodPost, "/admin/users/:id/suspend", "Suspend a user", dtos.UserDetailResponse{}),
+			action(http.MethodPost, "/admin/users/:id/unsuspend", "Unsuspend a user", dtos.UserDetailResponse{}),
+			action(http.MethodPost, "/admin/users/:id/reset-password", "Reset a user's password", dtos.PasswordResetResponse{}),
+			action(http.MethodPost, "/admin/users/:id/impersonate", "Issue a token acting as a user", dtos.ImpersonationResponse{}),
+			action(http.MethodPost, "/admin/users/:id/restore", "Restore a soft-deleted user", dtos.UserDetailResponse{}),
+			action(http.MethodDelete, "/admin/users/:id", "Soft-delete a user", dtos.UserDetailResponse{}),
+			action(http.MethodDelete, "/admin/users/:id/purge", "Permanently delete a user after the grace period", nil),
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware) {
+	users := router.Group("/admin/users", authMiddleware.RequireAuth, authMiddleware.RequireRole(models.RoleAdmin))
+
+	users.Get("/", controller.ListUsers)
+	users.Get("/:id", controller.GetUser)
+	users.Get("/:id/audit", controller.GetAuditTrail)
+	users.Post("/:id/suspend", controller.SuspendUser)
+	users.Post("/:id/unsuspend", controller.UnsuspendUser)
+	users.Post("/:id/reset-password", controller.ResetPassword)
+	users.Post("/:id/impersonate", controller.ImpersonateUser)
+	users.Post("/:id/restore", controller.RestoreUser)
+	users.Delete("/:id", controller.DeleteUser)
+	users.Delete("/:id/purge", controller.PurgeUser)
+}
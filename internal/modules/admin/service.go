@@ -0,0 +1,332 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/outbox"
+	"github.com/rakibulbanna/go-fiber-postgres/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actor identifies the admin performing an action, for the audit log.
+type Actor struct {
+	UserID uint
+	IP     string
+}
+
+// SystemActor performs the actions taken outside the API, with the admin
+// command. Its ID matches no user.
+var SystemActor = Actor{}
+
+type Service struct {
+	db               *gorm.DB
+	jwtSecret        string
+	impersonationTTL time.Duration
+	deleteGrace      time.Duration
+}
+
+func NewService(db *gorm.DB, jwtSecret string, impersonationTTL, deleteGrace time.Duration) *Service {
+	return &Service{
+		db:               db,
+		jwtSecret:        jwtSecret,
+		impersonationTTL: impersonationTTL,
+		deleteGrace:      deleteGrace,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func (s *Service) ListUsers(filter *dtos.UserFilter) ([]dtos.UserDetailResponse, int64, error) {
+	return s.users(s.db).ListUsers(filter)
+}
+
+func (s *Service) GetUser(userID uint) (*dtos.UserDetailResponse, error) {
+	return s.users(s.db).UserDetail(userID)
+}
+
+// Suspend disables the account and revokes its sessions, so they stay
+// invalid once the account is re-enabled.
+func (s *Service) Suspend(actor Actor, userID uint, reason string) (*dtos.UserDetailResponse, error) {
+	if actor.UserID == userID {
+		return nil, errors.New("you cannot suspend yourself")
+	}
+	return s.moderate(actor, userID, models.AuditUserSuspended, reason, func(tx *gorm.DB, _ *models.User) error {
+		users := s.users(tx)
+		if err := users.SetDisabled(userID, true); err != nil {
+			return err
+		}
+		return users.RevokeSessions(userID)
+	})
+}
+
+func (s *Service) Unsuspend(actor Actor, userID uint, reason string) (*dtos.UserDetailResponse, error) {
+	return s.moderate(actor, userID, models.AuditUserUnsuspended, reason, func(tx *gorm.DB, _ *models.User) error {
+		return s.users(tx).SetDisabled(userID, false)
+	})
+}
+
+// ResetPassword replaces the user's password with a random temporary one and
+// revokes their sessions. The password is returned to be passed on to the
+// user; it is not stored anywhere else.
+func (s *Service) ResetPassword(actor Actor, userID uint, reason string) (string, error) {
+	password, err := utils.GeneratePassword()
+	if err != nil {
+		return "", errors.New("failed to generate password")
+	}
+	if _, err := s.SetPassword(actor, userID, password, reason); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+// SetPassword replaces the user's password with the given one and revokes
+// their sessions.
+func (s *Service) SetPassword(actor Actor, userID uint, password, reason string) (*dtos.UserDetailResponse, error) {
+	return s.moderate(actor, userID, models.AuditUserPasswordReset, reason, func(tx *gorm.DB, _ *models.User) error {
+		return s.users(tx).ResetPassword(userID, password)
+	})
+}
+
+// SetRole promotes or demotes the user.
+func (s *Service) SetRole(actor Actor, userID uint, role, reason string) (*dtos.UserDetailResponse, error) {
+	return s.moderate(actor, userID, models.AuditUserRoleChanged, reason, func(tx *gorm.DB, _ *models.User) error {
+		return s.users(tx).SetRole(userID, role)
+	})
+}
+
+// RevokeSessions invalidates every token issued to the user so far.
+func (s *Service) RevokeSessions(actor Actor, userID uint, reason string) (*dtos.UserDetailResponse, error) {
+	return s.moderate(actor, userID, models.AuditUserSessionsRevoked, reason, func(tx *gorm.DB, _ *models.User) error {
+		return s.users(tx).RevokeSessions(userID)
+	})
+}
+
+// Impersonate issues a short-lived token letting the admin act as the user.
+// Requests made with it are logged with the admin's ID, and the token is
+// revoked along with the sessions of the user or the admin, or when the
+// admin is no longer an active admin.
+func (s *Service) Impersonate(actor Actor, userID uint, reason string) (*dtos.ImpersonationResponse, error) {
+	if actor.UserID == userID {
+		return nil, errors.New("you cannot impersonate yourself")
+	}
+
+	var token string
+	expiresAt := time.Now().Add(s.impersonationTTL)
+	detail, err := s.moderate(actor, userID, models.AuditUserImpersonated, reason, func(tx *gorm.DB, user *models.User) error {
+		switch {
+		case user.DeletedAt.Valid:
+			return errors.New("cannot impersonate a deleted user")
+		case user.DisabledAt != nil:
+			return errors.New("cannot impersonate a suspended user")
+		case user.Role == models.RoleAdmin:
+			return errors.New("cannot impersonate an admin")
+		}
+
+		// Revoking the admin's sessions revokes the token too
+		var admin models.User
+		if err := tx.Select("id", "token_version").First(&admin, actor.UserID).Error; err != nil {
+			return errors.New("admin not found")
+		}
+
+		var err error
+		token, err = utils.GenerateImpersonationToken(user.ID, user.Email, user.TokenVersion, admin.ID, admin.TokenVersion, expiresAt, s.jwtSecret)
+		if err != nil {
+			return errors.New("failed to generate token")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.ImpersonationResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      *detail,
+	}, nil
+}
+
+// Delete soft-deletes the user and revokes their sessions. Their data is
+// kept until the user is purged, which the grace period delays.
+func (s *Service) Delete(actor Actor, userID uint, reason string) (*dtos.UserDetailResponse, error) {
+	if actor.UserID == userID {
+		return nil, errors.New("you cannot delete yourself")
+	}
+	return s.moderate(actor, userID, models.AuditUserDeleted, reason, func(tx *gorm.DB, user *models.User) error {
+		if user.DeletedAt.Valid {
+			return errors.New("user is already deleted")
+		}
+		if err := s.users(tx).RevokeSessions(userID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.User{}, userID).Error; err != nil {
+			return errors.New("failed to delete user")
+		}
+		return nil
+	})
+}
+
+// Restore undoes a soft delete during the grace period.
+func (s *Service) Restore(actor Actor, userID uint, reason string) (*dtos.UserDetailResponse, error) {
+	return s.moderate(actor, userID, models.AuditUserRestored, reason, func(tx *gorm.DB, user *models.User) error {
+		if !user.DeletedAt.Valid {
+			return errors.New("user is not deleted")
+		}
+		if err := tx.Unscoped().Model(&models.User{}).Where("id = ?", userID).Update("deleted_at", nil).Error; err != nil {
+			return errors.New("failed to restore user")
+		}
+		return nil
+	})
+}
+
+// Purge permanently removes a soft-deleted user and everything they own once
+// the grace period has passed. Book ratings are recomputed without the
+// user's reviews.
+func (s *Service) Purge(actor Actor, userID uint, reason string) error {
+	_, err := s.moderate(actor, userID, models.AuditUserPurged, reason, func(tx *gorm.DB, user *models.User) error {
+		if !user.DeletedAt.Valid {
+			return errors.New("user must be deleted before being purged")
+		}
+		if purgeAt := user.DeletedAt.Time.Add(s.deleteGrace); time.Now().Before(purgeAt) {
+			return errors.New("user cannot be purged before " + purgeAt.UTC().Format(time.RFC3339))
+		}
+		return purgeUser(tx, userID)
+	})
+	return err
+}
+
+// AuditTrail returns the admin actions taken on the user, newest first.
+func (s *Service) AuditTrail(userID uint) ([]dtos.AuditLogResponse, error) {
+	var logs []dtos.AuditLogResponse
+	err := s.db.Table("audit_logs").
+		Select("audit_logs.*, users.email AS actor_email").
+		Joins("LEFT JOIN users ON users.id = audit_logs.actor_id").
+		Where("audit_logs.target_user_id = ?", userID).
+		Order("audit_logs.created_at DESC, audit_logs.id DESC").
+		Scan(&logs).Error
+	if err != nil {
+		return nil, errors.New("failed to fetch audit log")
+	}
+	return logs, nil
+}
+
+// moderate runs apply in one transaction with the target user locked,
+// records the audit entry and the event of the change, and returns the user's updated details, nil once
+// purged.
+func (s *Service) moderate(actor Actor, userID uint, action, reason string, apply func(tx *gorm.DB, user *models.User) error) (*dtos.UserDetailResponse, error) {
+	var detail *dtos.UserDetailResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+
+		if err := apply(tx, &user); err != nil {
+			return err
+		}
+
+		entry := models.AuditLog{
+			ActorID:      actor.UserID,
+			Action:       action,
+			TargetUserID: userID,
+			Reason:       reason,
+			IP:           actor.IP,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return errors.New("failed to record audit log")
+		}
+
+		var data interface{} = map[string]uint{"id": userID}
+		if action != models.AuditUserPurged {
+			var err error
+			if detail, err = s.users(tx).UserDetail(userID); err != nil {
+				return err
+			}
+			data = detail
+		}
+
+		if eventType, ok := userEvents[action]; ok {
+			if err := outbox.Write(tx, events.New(eventType, userID, userID, data)); err != nil {
+				return errors.New("failed to record event")
+			}
+		}
+		return nil
+	})
+	return detail, err
+}
+
+// userEvents maps the audited actions that change a user's account to the
+// events they record.
+var userEvents = map[string]string{
+	models.AuditUserSuspended:   events.UserSuspended,
+	models.AuditUserUnsuspended: events.UserUnsuspended,
+	models.AuditUserDeleted:     events.UserDeleted,
+	models.AuditUserRestored:    events.UserRestored,
+	models.AuditUserPurged:      events.UserPurged,
+}
+
+// users returns the auth service the shared account operations live on.
+func (s *Service) users(db *gorm.DB) *auth.Service {
+	return auth.NewService(db, s.jwtSecret)
+}
+
+// purgeUser deletes the user and everything that references them. Reviews
+// on other users' books are removed first so those books' ratings can be
+// recomputed; shelf entries, loan events and anything on the user's own
+// books cascade. Each of their books records a book.deleted event, as when
+// it is deleted through the API.
+func purgeUser(tx *gorm.DB, userID uint) error {
+	var bookIDs []uint
+	if err := tx.Model(&models.Review{}).Where("user_id = ?", userID).Pluck("book_id", &bookIDs).Error; err != nil {
+		return errors.New("failed to purge user")
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Review{}).Error; err != nil {
+		return errors.New("failed to delete reviews")
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Shelf{}).Error; err != nil {
+		return errors.New("failed to delete shelves")
+	}
+	if err := tx.Where("owner_id = ? OR borrower_id = ?", userID, userID).Delete(&models.Loan{}).Error; err != nil {
+		return errors.New("failed to delete loans")
+	}
+	var books []models.Book
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Find(&books).Error; err != nil {
+		return errors.New("failed to delete books")
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.Book{}).Error; err != nil {
+		return errors.New("failed to delete books")
+	}
+	for i := range books {
+		if err := outbox.Write(tx, book.BookEvent(events.BookDeleted, &books[i])); err != nil {
+			return errors.New("failed to record event")
+		}
+	}
+	if err := tx.Unscoped().Delete(&models.User{}, userID).Error; err != nil {
+		return errors.New("failed to delete user")
+	}
+
+	if len(bookIDs) == 0 {
+		return nil
+	}
+	err := tx.Model(&models.Book{}).Where("id IN ?", bookIDs).Updates(map[string]interface{}{
+		"review_count":   gorm.Expr("(SELECT COUNT(*) FROM reviews WHERE reviews.book_id = books.id)"),
+		"average_rating": gorm.Expr("COALESCE((SELECT AVG(rating) FROM reviews WHERE reviews.book_id = books.id), 0)"),
+	}).Error
+	if err != nil {
+		return errors.New("failed to update book ratings")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+)
+
+func TestSystemActor(t *testing.T) {
+	tests := []struct {
+		name      string
+		do        func(s *Service, userID uint) error
+		wantAudit string
+		// wantEvent is the outbox event recorded, if any
+		wantEvent string
+	}{
+		{
+			name: "set password",
+			do: func(s *Service, userID uint) error {
+				_, err := s.SetPassword(SystemActor, userID, "new-password", "cli")
+				return err
+			},
+			wantAudit: models.AuditUserPasswordReset,
+		},
+		{
+			name: "set role",
+			do: func(s *Service, userID uint) error {
+				_, err := s.SetRole(SystemActor, userID, models.RoleAdmin, "cli")
+				return err
+			},
+			wantAudit: models.AuditUserRoleChanged,
+		},
+		{
+			name: "revoke sessions",
+			do: func(s *Service, userID uint) error {
+				_, err := s.RevokeSessions(SystemActor, userID, "cli")
+				return err
+			},
+			wantAudit: models.AuditUserSessionsRevoked,
+		},
+		{
+			name: "suspend",
+			do: func(s *Service, userID uint) error {
+				_, err := s.Suspend(SystemActor, userID, "cli")
+				return err
+			},
+			wantAudit: models.AuditUserSuspended,
+			wantEvent: events.UserSuspended,
+		},
+		{
+			name: "unsuspend",
+			do: func(s *Service, userID uint) error {
+				_, err := s.Unsuspend(SystemActor, userID, "cli")
+				return err
+			},
+			wantAudit: models.AuditUserUnsuspended,
+			wantEvent: events.UserUnsuspended,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := storagetest.Open(t, &models.User{}, &models.AuditLog{}, &models.OutboxEvent{})
+			user := models.User{Email: "alice@example.com", Password: "x", Name: "Alice", Role: models.RoleUser}
+			if err := db.Create(&user).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			service := NewService(db, "secret", time.Hour, time.Hour)
+			if err := tt.do(service, user.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			var logs []models.AuditLog
+			if err := db.Find(&logs).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(logs) != 1 || logs[0].Action != tt.wantAudit || logs[0].ActorID != 0 || logs[0].TargetUserID != user.ID || logs[0].Reason != "cli" {
+				t.Errorf("audit log = %+v, want one %s entry by actor 0", logs, tt.wantAudit)
+			}
+
+			var outbox []models.OutboxEvent
+			if err := db.Find(&outbox).Error; err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.wantEvent == "" && len(outbox) != 0:
+				t.Errorf("outbox = %+v, want none", outbox)
+			case tt.wantEvent != "" && (len(outbox) != 1 || outbox[0].Type != tt.wantEvent):
+				t.Errorf("outbox = %+v, want one %s event", outbox, tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestPurgeBookEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		books []string
+	}{
+		{name: "no books"},
+		{name: "books", books: []string{"Dune", "Emma"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := storagetest.Open(t, &models.User{}, &models.Book{}, &models.Review{}, &models.Shelf{}, &models.ShelfEntry{},
+				&models.Loan{}, &models.LoanEvent{}, &models.AuditLog{}, &models.OutboxEvent{})
+			user := models.User{Email: "alice@example.com", Password: "x", Name: "Alice", Role: models.RoleUser}
+			if err := db.Create(&user).Error; err != nil {
+				t.Fatal(err)
+			}
+			for _, title := range tt.books {
+				if err := db.Create(&models.Book{UserID: user.ID, Title: title, Publisher: "Chilton", Year: 1965}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			service := NewService(db, "secret", time.Hour, 0)
+			if _, err := service.Delete(SystemActor, user.ID, ""); err != nil {
+				t.Fatal(err)
+			}
+			if err := service.Purge(SystemActor, user.ID, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			var got int64
+			if err := db.Model(&models.OutboxEvent{}).Where("type = ?", events.BookDeleted).Count(&got).Error; err != nil {
+				t.Fatal(err)
+			}
+			if got != int64(len(tt.books)) {
+				t.Errorf("%d book.deleted events, want %d", got, len(tt.books))
+			}
+		})
+	}
+}
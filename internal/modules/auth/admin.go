@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/utils"
+	"gorm.io/gorm"
+)
+
+// Administrative operations, used by the admin CLI.
+
+// CreateUser creates a user with the given role, "user" when empty.
+func (s *Service) CreateUser(req *dtos.CreateUserRequest) (*dtos.UserDetailResponse, error) {
+	role := req.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+	if !models.IsValidRole(role) {
+		return nil, errors.New("invalid role, must be user or admin")
+	}
+	if len(req.Password) < 6 {
+		return nil, errors.New("password must be at least 6 characters")
+	}
+
+	var existingUser models.User
+	if err := s.db.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+		return nil, errors.New("user with this email already exists")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	user := &models.User{
+		Email:    req.Email,
+		Password: hashedPassword,
+		Name:     req.Name,
+		Role:     role,
+	}
+	if err := s.createUser(user); err != nil {
+		return nil, err
+	}
+	return toUserDetail(user), nil
+}
+
+// FindUser looks a user up by numeric ID or by email.
+func (s *Service) FindUser(identifier string) (*models.User, error) {
+	var user models.User
+	query := s.db.Where("email = ?", identifier)
+	if id, err := strconv.ParseUint(identifier, 10, 64); err == nil {
+		query = s.db.Where("id = ?", id)
+	}
+	if err := query.First(&user).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	return &user, nil
+}
+
+// UserDetail returns the administrative view of one user, including
+// soft-deleted ones.
+func (s *Service) UserDetail(userID uint) (*dtos.UserDetailResponse, error) {
+	var user models.User
+	if err := s.db.Unscoped().First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	return toUserDetail(&user), nil
+}
+
+// ResetPassword sets a new password and revokes the user's sessions.
+func (s *Service) ResetPassword(userID uint, password string) error {
+	if len(password) < 6 {
+		return errors.New("password must be at least 6 characters")
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	return s.updateUser(userID, map[string]interface{}{
+		"password":      hashedPassword,
+		"token_version": gorm.Expr("token_version + 1"),
+	})
+}
+
+// SetRole promotes or demotes a user. Roles are read on every request, so
+// the change applies to existing sessions immediately.
+func (s *Service) SetRole(userID uint, role string) error {
+	if !models.IsValidRole(role) {
+		return errors.New("invalid role, must be user or admin")
+	}
+	return s.updateUser(userID, map[string]interface{}{"role": role})
+}
+
+// SetDisabled disables or re-enables an account. Disabled users cannot log
+// in and their existing tokens are rejected.
+func (s *Service) SetDisabled(userID uint, disabled bool) error {
+	var disabledAt *time.Time
+	if disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+	return s.updateUser(userID, map[string]interface{}{"disabled_at": disabledAt})
+}
+
+// RevokeSessions invalidates every token issued to the user so far.
+func (s *Service) RevokeSessions(userID uint) error {
+	return s.updateUser(userID, map[string]interface{}{"token_version": gorm.Expr("token_version + 1")})
+}
+
+// ListUsers returns a page of the users matching filter, ordered by ID, and
+// the total number of matches.
+func (s *Service) ListUsers(filter *dtos.UserFilter) ([]dtos.UserDetailResponse, int64, error) {
+	query := s.db.Model(&models.User{})
+	if filter.Search != "" {
+		pattern := utils.LikeContains(strings.ToLower(filter.Search))
+		query = query.Where(`LOWER(email) LIKE ? ESCAPE '\' OR LOWER(name) LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	switch filter.Status {
+	case "":
+	case "active":
+		query = query.Where("disabled_at IS NULL")
+	case "disabled":
+		query = query.Where("disabled_at IS NOT NULL")
+	case "deleted":
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	default:
+		return nil, 0, errors.New("invalid status, must be active, disabled or deleted")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count users")
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var users []models.User
+	if err := query.Order("id").Find(&users).Error; err != nil {
+		return nil, 0, errors.New("failed to fetch users")
+	}
+
+	responses := make([]dtos.UserDetailResponse, len(users))
+	for i := range users {
+		responses[i] = *toUserDetail(&users[i])
+	}
+	return responses, total, nil
+}
+
+// ValidateSession implements middleware.SessionValidator: it rejects tokens
+// of disabled or deleted users and tokens issued before the last revocation,
+// and returns the user's current role. Impersonation tokens must pass the
+// same checks for the impersonator, who must still be an admin.
+func (s *Service) ValidateSession(ctx context.Context, claims *utils.Claims) (string, error) {
+	user, err := s.checkSession(ctx, claims.UserID, claims.TokenVersion)
+	if err != nil {
+		return "", err
+	}
+	if claims.ImpersonatorID != 0 {
+		impersonator, err := s.checkSession(ctx, claims.ImpersonatorID, claims.ImpersonatorTokenVersion)
+		if err != nil {
+			return "", fmt.Errorf("impersonator: %w", err)
+		}
+		if impersonator.Role != models.RoleAdmin {
+			return "", errors.New("impersonator is no longer an admin")
+		}
+	}
+	return user.Role, nil
+}
+
+func (s *Service) checkSession(ctx context.Context, userID uint, tokenVersion int) (*models.User, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "role", "disabled_at", "token_version").First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.DisabledAt != nil {
+		return nil, errors.New("account is disabled")
+	}
+	if user.TokenVersion != tokenVersion {
+		return nil, errors.New("session has been revoked")
+	}
+	return &user, nil
+}
+
+func (s *Service) updateUser(userID uint, updates map[string]interface{}) error {
+	result := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
+	if result.Error != nil {
+		return errors.New("failed to update user")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+func toUserDetail(user *models.User) *dtos.UserDetailResponse {
+	var deletedAt *time.Time
+	if user.DeletedAt.Valid {
+		deletedAt = &user.DeletedAt.Time
+	}
+	return &dtos.UserDetailResponse{
+		ID:         user.ID,
+		Email:      user.Email,
+		Name:       user.Name,
+		Role:       user.Role,
+		Disabled:   user.DisabledAt != nil,
+		DisabledAt: user.DisabledAt,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+		DeletedAt:  deletedAt,
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+	"github.com/rakibulbanna/go-fiber-postgres/utils"
+)
+
+func newTestService(t *testing.T, users ...models.User) *Service {
+	t.Helper()
+
+	db := storagetest.Open(t, &models.User{})
+	for i := range users {
+		if err := db.Create(&users[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewService(db, "secret")
+}
+
+func TestValidateSession(t *testing.T) {
+	disabledAt := time.Now()
+	service := newTestService(t,
+		models.User{ID: 1, Email: "user@example.com", Name: "User", Password: "x", Role: models.RoleUser, TokenVersion: 2},
+		models.User{ID: 2, Email: "admin@example.com", Name: "Admin", Password: "x", Role: models.RoleAdmin, TokenVersion: 1},
+		models.User{ID: 3, Email: "demoted@example.com", Name: "Demoted", Password: "x", Role: models.RoleUser},
+		models.User{ID: 4, Email: "suspended@example.com", Name: "Suspended", Password: "x", Role: models.RoleAdmin, DisabledAt: &disabledAt},
+	)
+
+	tests := []struct {
+		name     string
+		claims   utils.Claims
+		wantRole string
+		wantErr  bool
+	}{
+		{name: "user", claims: utils.Claims{UserID: 1, TokenVersion: 2}, wantRole: models.RoleUser},
+		{name: "admin", claims: utils.Claims{UserID: 2, TokenVersion: 1}, wantRole: models.RoleAdmin},
+		{name: "revoked", claims: utils.Claims{UserID: 1, TokenVersion: 1}, wantErr: true},
+		{name: "disabled", claims: utils.Claims{UserID: 4}, wantErr: true},
+		{name: "unknown user", claims: utils.Claims{UserID: 9}, wantErr: true},
+		{
+			name:     "impersonation by active admin",
+			claims:   utils.Claims{UserID: 1, TokenVersion: 2, ImpersonatorID: 2, ImpersonatorTokenVersion: 1},
+			wantRole: models.RoleUser,
+		},
+		{
+			name:    "impersonator sessions revoked",
+			claims:  utils.Claims{UserID: 1, TokenVersion: 2, ImpersonatorID: 2, ImpersonatorTokenVersion: 0},
+			wantErr: true,
+		},
+		{
+			name:    "impersonator demoted",
+			claims:  utils.Claims{UserID: 1, TokenVersion: 2, ImpersonatorID: 3},
+			wantErr: true,
+		},
+		{
+			name:    "impersonator suspended",
+			claims:  utils.Claims{UserID: 1, TokenVersion: 2, ImpersonatorID: 4},
+			wantErr: true,
+		},
+		{
+			name:    "impersonator deleted",
+			claims:  utils.Claims{UserID: 1, TokenVersion: 2, ImpersonatorID: 9},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, err := service.ValidateSession(context.Background(), &tt.claims)
+			if (err != nil) != tt.wantErr || role != tt.wantRole {
+				t.Errorf("got role %q and error %v, want role %q and error %v", role, err, tt.wantRole, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListUsersSearch(t *testing.T) {
+	service := newTestService(t,
+		models.User{Email: "ann@example.com", Name: "Ann 100% Real", Password: "x"},
+		models.User{Email: "bob_smith@example.com", Name: "Bob", Password: "x"},
+		models.User{Email: "bobxsmith@example.com", Name: "Bobby", Password: "x"},
+		models.User{Email: "carol@example.com", Name: `C\arol`, Password: "x"},
+	)
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"BOB", []string{"bob_smith@example.com", "bobxsmith@example.com"}},
+		{"bob_", []string{"bob_smith@example.com"}},
+		{"%", []string{"ann@example.com"}},
+		{"_", []string{"bob_smith@example.com"}},
+		{`\a`, []string{"carol@example.com"}},
+		{"nobody", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			users, total, err := service.ListUsers(&dtos.UserFilter{Search: tt.search})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, user := range users {
+				got = append(got, user.Email)
+			}
+			if total != int64(len(tt.want)) || len(got) != len(tt.want) {
+				t.Fatalf("got %v (total %d), want %v", got, total, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -35,7 +35,7 @@ func (c *Controller) SignUp(ctx *fiber.Ctx) error {
 		})
 	}
 
-	response, err := c.service.SignUp(&req)
+	response, err := c.service.WithContext(ctx.UserContext()).SignUp(&req)
 	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
@@ -63,7 +63,7 @@ func (c *Controller) Login(ctx *fiber.Ctx) error {
 		})
 	}
 
-	response, err := c.service.Login(&req)
+	response, err := c.service.WithContext(ctx.UserContext()).Login(&req)
 	if err != nil {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": err.Error(),
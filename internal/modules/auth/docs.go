@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// Docs describes the routes registered by SetupRoutes for the OpenAPI document.
+func Docs(version int) openapi.Module {
+	routes := []openapi.Route{
+		{Method: http.MethodPost, Path: "/auth/signup", Summary: "Create an account", Request: dtos.SignUpRequest{}, Status: http.StatusCreated, Response: dtos.AuthResponse{}},
+		{Method: http.MethodPost, Path: "/auth/login", Summary: "Log in", Request: dtos.LoginRequest{}, Response: dtos.AuthResponse{}},
+	}
+	if version == 1 {
+		routes = append(routes, openapi.Route{Method: http.MethodPost, Path: "/auth/signin", Summary: "Log in (alias of /auth/login)", Request: dtos.LoginRequest{}, Response: dtos.AuthResponse{}})
+	}
+
+	return openapi.Module{
+		Tag:         "Auth",
+		Description: "Sign up and obtain bearer tokens",
+		Routes:      routes,
+	}
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+	bookapiv1 "github.com/rakibulbanna/go-fiber-postgres/proto/bookapi/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// GRPCServer serves bookapi.v1.AuthService from the same Service as the
+// HTTP controller.
+type GRPCServer struct {
+	bookapiv1.UnimplementedAuthServiceServer
+	service *Service
+}
+
+func NewGRPCServer(service *Service) *GRPCServer {
+	return &GRPCServer{service: service}
+}
+
+func (s *GRPCServer) SignUp(ctx context.Context, req *bookapiv1.SignUpRequest) (*bookapiv1.AuthResponse, error) {
+	if req.GetEmail() == "" || req.GetPassword() == "" || req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Email, password, and name are required")
+	}
+	if len(req.GetPassword()) < 6 {
+		return nil, status.Error(codes.InvalidArgument, "Password must be at least 6 characters")
+	}
+
+	response, err := s.service.WithContext(ctx).SignUp(&dtos.SignUpRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Name:     req.GetName(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toProtoAuthResponse(response), nil
+}
+
+func (s *GRPCServer) Login(ctx context.Context, req *bookapiv1.LoginRequest) (*bookapiv1.AuthResponse, error) {
+	if req.GetEmail() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Email and password are required")
+	}
+
+	response, err := s.service.WithContext(ctx).Login(&dtos.LoginRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return toProtoAuthResponse(response), nil
+}
+
+func (s *GRPCServer) Me(ctx context.Context, _ *emptypb.Empty) (*bookapiv1.User, error) {
+	caller, ok := middleware.GRPCUserFrom(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "User not authenticated")
+	}
+
+	user, err := s.service.WithContext(ctx).FindUserByID(caller.ID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &bookapiv1.User{Id: uint32(user.ID), Email: user.Email, Name: user.Name}, nil
+}
+
+func toProtoAuthResponse(response *dtos.AuthResponse) *bookapiv1.AuthResponse {
+	return &bookapiv1.AuthResponse{
+		Token: response.Token,
+		User: &bookapiv1.User{
+			Id:    uint32(response.User.ID),
+			Email: response.User.Email,
+			Name:  response.User.Name,
+		},
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(router fiber.Router, controller *Controller) {
+// SetupRoutes registers the routes of the given API version.
+func SetupRoutes(router fiber.Router, controller *Controller, version int) {
 	auth := router.Group("/auth")
-	
+
 	auth.Post("/signup", controller.SignUp)
 	auth.Post("/login", controller.Login)
-	auth.Post("/signin", controller.Login) // Alias for login
+	if version == 1 {
+		auth.Post("/signin", controller.Login) // Alias for login, dropped in v2
+	}
 }
-
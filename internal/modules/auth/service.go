@@ -1,10 +1,14 @@
 package auth
 
 import (
+	"context"
 	"errors"
 
 	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
 	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/outbox"
 	"github.com/rakibulbanna/go-fiber-postgres/utils"
 	"gorm.io/gorm"
 )
@@ -21,6 +25,13 @@ func NewService(db *gorm.DB, jwtSecret string) *Service {
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
 func (s *Service) SignUp(req *dtos.SignUpRequest) (*dtos.AuthResponse, error) {
 	// Check if user already exists
 	var existingUser models.User
@@ -39,14 +50,16 @@ func (s *Service) SignUp(req *dtos.SignUpRequest) (*dtos.AuthResponse, error) {
 		Email:    req.Email,
 		Password: hashedPassword,
 		Name:     req.Name,
+		Role:     models.RoleUser,
 	}
 
-	if err := s.db.Create(user).Error; err != nil {
-		return nil, errors.New("failed to create user")
+	if err := s.createUser(user); err != nil {
+		return nil, err
 	}
+	metrics.SignUps.Inc()
 
 	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email, s.jwtSecret)
+	token, err := utils.GenerateToken(user.ID, user.Email, user.TokenVersion, s.jwtSecret)
 	if err != nil {
 		return nil, errors.New("failed to generate token")
 	}
@@ -61,20 +74,40 @@ func (s *Service) SignUp(req *dtos.SignUpRequest) (*dtos.AuthResponse, error) {
 	}, nil
 }
 
+// createUser inserts user and records its user.created event in the same
+// transaction.
+func (s *Service) createUser(user *models.User) error {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, events.New(events.UserCreated, user.ID, user.ID, toUserDetail(user)))
+	}); err != nil {
+		return errors.New("failed to create user")
+	}
+	return nil
+}
+
 func (s *Service) Login(req *dtos.LoginRequest) (*dtos.AuthResponse, error) {
 	// Find user by email
 	var user models.User
 	if err := s.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		metrics.LoginFailures.Inc()
 		return nil, errors.New("invalid email or password")
 	}
 
 	// Check password
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		metrics.LoginFailures.Inc()
 		return nil, errors.New("invalid email or password")
 	}
+	if user.DisabledAt != nil {
+		metrics.LoginFailures.Inc()
+		return nil, errors.New("account is disabled")
+	}
 
 	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.Email, s.jwtSecret)
+	token, err := utils.GenerateToken(user.ID, user.Email, user.TokenVersion, s.jwtSecret)
 	if err != nil {
 		return nil, errors.New("failed to generate token")
 	}
@@ -97,3 +130,12 @@ func (s *Service) FindUserByID(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+// FindUsersByIDs returns the users with the given IDs in no particular
+// order, leaving out those that do not exist.
+func (s *Service) FindUsersByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	if err := s.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, errors.New("failed to fetch users")
+	}
+	return users, nil
+}
@@ -1,10 +1,19 @@
 package book
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
 )
 
 type Controller struct {
@@ -15,7 +24,55 @@ func NewController(service *Service) *Controller {
 	return &Controller{service: service}
 }
 
+// errorStatus maps the errors of lookups, updates and deletes to statuses.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrBookNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		return fiber.StatusForbidden
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
 func (c *Controller) CreateBook(ctx *fiber.Ctx) error {
+	return c.createBook(ctx, func(book *models.Book) error {
+		return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "Book created successfully",
+			"data":    ToBookResponse(book),
+		})
+	})
+}
+
+func (c *Controller) GetBooks(ctx *fiber.Ctx) error {
+	return c.getBooks(ctx, func(books []models.Book) error {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"data": books,
+		})
+	})
+}
+
+func (c *Controller) GetBook(ctx *fiber.Ctx) error {
+	return c.getBook(ctx, func(book *models.Book) error {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"data": book,
+		})
+	})
+}
+
+func (c *Controller) UpdateBook(ctx *fiber.Ctx) error {
+	return c.updateBook(ctx, func(book *models.Book) error {
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Book updated successfully",
+			"data":    ToBookResponse(book),
+		})
+	})
+}
+
+// createBook, getBooks, getBook and updateBook handle the request and leave
+// the successful response to respond, which differs between API versions.
+func (c *Controller) createBook(ctx *fiber.Ctx, respond func(book *models.Book) error) error {
 	var req dtos.CreateBookRequest
 
 	if err := ctx.BodyParser(&req); err != nil {
@@ -38,33 +95,35 @@ func (c *Controller) CreateBook(ctx *fiber.Ctx) error {
 		})
 	}
 
-	book, err := c.service.CreateBook(userID, &req)
+	book, err := c.service.WithContext(ctx.UserContext()).CreateBook(userID, &req)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Book created successfully",
-		"data":    book,
-	})
+	return respond(book)
 }
 
-func (c *Controller) GetBooks(ctx *fiber.Ctx) error {
-	books, err := c.service.GetAllBooks()
+func (c *Controller) getBooks(ctx *fiber.Ctx, respond func(books []models.Book) error) error {
+	var filter dtos.BookFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid query parameters",
+		})
+	}
+
+	books, err := c.service.WithContext(ctx.UserContext()).GetAllBooks(&filter)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"data": books,
-	})
+	return respond(books)
 }
 
-func (c *Controller) GetBook(ctx *fiber.Ctx) error {
+func (c *Controller) getBook(ctx *fiber.Ctx, respond func(book *models.Book) error) error {
 	idParam := ctx.Params("id")
 	if idParam == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -79,19 +138,17 @@ func (c *Controller) GetBook(ctx *fiber.Ctx) error {
 		})
 	}
 
-	book, err := c.service.GetBookByID(uint(id))
+	book, err := c.service.WithContext(ctx.UserContext()).GetBookByID(uint(id))
 	if err != nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"data": book,
-	})
+	return respond(book)
 }
 
-func (c *Controller) UpdateBook(ctx *fiber.Ctx) error {
+func (c *Controller) updateBook(ctx *fiber.Ctx, respond func(book *models.Book) error) error {
 	idParam := ctx.Params("id")
 	if idParam == "" {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -122,17 +179,14 @@ func (c *Controller) UpdateBook(ctx *fiber.Ctx) error {
 		})
 	}
 
-	book, err := c.service.UpdateBook(uint(id), userID, &req)
+	book, err := c.service.WithContext(ctx.UserContext()).UpdateBook(uint(id), userID, &req)
 	if err != nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Book updated successfully",
-		"data":    book,
-	})
+	return respond(book)
 }
 
 func (c *Controller) DeleteBook(ctx *fiber.Ctx) error {
@@ -158,8 +212,8 @@ func (c *Controller) DeleteBook(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if err := c.service.DeleteBook(uint(id), userID); err != nil {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+	if err := c.service.WithContext(ctx.UserContext()).DeleteBook(uint(id), userID); err != nil {
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -169,3 +223,159 @@ func (c *Controller) DeleteBook(ctx *fiber.Ctx) error {
 	})
 }
 
+func (c *Controller) ImportBooks(ctx *fiber.Ctx) error {
+	mode := ctx.Query("mode", ImportModeTransactional)
+	if mode != ImportModeTransactional && mode != ImportModeBestEffort {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Mode must be transactional or best_effort",
+		})
+	}
+	dryRun := ctx.QueryBool("dry_run", false)
+
+	// Accept either a raw body or a multipart upload in the "file" field
+	body := ctx.Body()
+	format := ctx.Query("format")
+	if file, err := ctx.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid upload",
+			})
+		}
+		defer f.Close()
+
+		if body, err = io.ReadAll(f); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid upload",
+			})
+		}
+		if format == "" {
+			format = importFormatFromName(file.Filename)
+		}
+	}
+	if format == "" {
+		format = importFormatFromContentType(string(ctx.Request().Header.ContentType()))
+	}
+	if format != ImportFormatCSV && format != ImportFormatNDJSON {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Format must be csv or ndjson",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	rows, parseErrors, err := parseImport(bytes.NewReader(body), format)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	report, err := c.service.WithContext(ctx.UserContext()).ImportBooks(userID, rows, parseErrors, mode, dryRun)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	status := fiber.StatusOK
+	if report.Imported > 0 {
+		status = fiber.StatusCreated
+	}
+
+	// Let clients download the per-row error report directly
+	if ctx.Query("report") == "csv" {
+		ctx.Set(fiber.HeaderContentType, "text/csv")
+		ctx.Attachment("import-report.csv")
+		ctx.Status(status)
+		return writeImportReport(ctx, report)
+	}
+
+	message := "Books imported successfully"
+	if dryRun {
+		message = "Dry run completed"
+	} else if report.Imported == 0 && report.Failed > 0 {
+		message = "No books were imported"
+	}
+
+	return ctx.Status(status).JSON(fiber.Map{
+		"message": message,
+		"data":    report,
+	})
+}
+
+func importFormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return ImportFormatNDJSON
+	}
+	return ""
+}
+
+func importFormatFromContentType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return ImportFormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"),
+		strings.HasPrefix(contentType, "application/ndjson"),
+		strings.HasPrefix(contentType, "application/jsonl"):
+		return ImportFormatNDJSON
+	}
+	return ""
+}
+
+func (c *Controller) ExportBooks(ctx *fiber.Ctx) error {
+	format := ctx.Query("format", "csv")
+	exportFormat, ok := ExportFormats[format]
+	if !ok {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Format must be csv, ndjson, xlsx or marc",
+		})
+	}
+
+	var filter dtos.BookFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid query parameters",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if ctx.QueryBool("mine", false) {
+		filter.UserID = userID
+	}
+
+	ctx.Set(fiber.HeaderContentType, exportFormat.ContentType)
+	ctx.Attachment("books." + exportFormat.Extension)
+
+	// The body is written after the handler returns, row by row from a DB cursor,
+	// so nothing borrowed from ctx may be used inside the stream writer
+	service := c.service.WithContext(ctx.UserContext())
+	format = utils.CopyString(format)
+	filter.Title = utils.CopyString(filter.Title)
+	filter.Author = utils.CopyString(filter.Author)
+	filter.Publisher = utils.CopyString(filter.Publisher)
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := service.ExportBooks(w, &filter, format); err != nil {
+			slog.Error("Book export failed", "format", format, "error", err)
+		}
+		w.Flush()
+	})
+	return nil
+}
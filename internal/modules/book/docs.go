@@ -0,0 +1,80 @@
+package book
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// Docs describes the routes registered by SetupRoutes for the OpenAPI document.
+func Docs(version int) openapi.Module {
+	exportTypes := make([]string, 0, len(ExportFormats))
+	for _, name := range []string{"csv", "ndjson", "xlsx", "marc"} {
+		exportTypes = append(exportTypes, ExportFormats[name].ContentType)
+	}
+
+	module := openapi.Module{
+		Tag:         "Books",
+		Description: "The book catalog",
+		Routes: []openapi.Route{
+			{Method: http.MethodGet, Path: "/books/", Summary: "List books", Auth: openapi.Optional, Query: dtos.BookFilter{}, Response: []models.Book{}},
+			{
+				Method: http.MethodGet, Path: "/books/export", Summary: "Export books", Auth: openapi.Authenticated,
+				Query: dtos.BookFilter{},
+				Params: []openapi.Parameter{
+					openapi.QueryParam("format", "string", "Download format, csv by default", "csv", "ndjson", "xlsx", "marc"),
+					openapi.QueryParam("mine", "boolean", "Only export your own books"),
+				},
+				ResponseTypes: exportTypes,
+			},
+			{Method: http.MethodGet, Path: "/books/:id", Summary: "Get a book", Auth: openapi.Optional, Response: models.Book{}},
+			{Method: http.MethodPost, Path: "/books/", Summary: "Create a book", Auth: openapi.Authenticated, Request: dtos.CreateBookRequest{}, Status: http.StatusCreated, Response: dtos.BookResponse{}},
+			{
+				Method: http.MethodPost, Path: "/books/import", Summary: "Import books from CSV or NDJSON", Auth: openapi.Authenticated,
+				Description: "Responds 201 when at least one book was imported, 200 otherwise.",
+				Params: []openapi.Parameter{
+					openapi.QueryParam("mode", "string", "transactional imports all rows or none", ImportModeTransactional, ImportModeBestEffort),
+					openapi.QueryParam("dry_run", "boolean", "Validate without importing"),
+					openapi.QueryParam("format", "string", "Body format, detected from the file name or content type by default", ImportFormatCSV, ImportFormatNDJSON),
+					openapi.QueryParam("report", "string", "Download the per-row report as CSV instead", "csv"),
+				},
+				RequestTypes: []string{"text/csv", "application/x-ndjson"},
+				FileField:    "file",
+				Response:     dtos.ImportBooksResponse{},
+			},
+			{Method: http.MethodPut, Path: "/books/:id", Summary: "Update one of your books", Auth: openapi.Authenticated, Request: dtos.UpdateBookRequest{}, Response: dtos.BookResponse{}},
+			{Method: http.MethodDelete, Path: "/books/:id", Summary: "Delete one of your books", Auth: openapi.Authenticated},
+		},
+	}
+	if version >= 2 {
+		envelopeDocs(module.Routes)
+	}
+	return module
+}
+
+// envelopeDocs adapts the routes to version 2, which returns books in a
+// dtos.Envelope with sparse fieldsets.
+func envelopeDocs(routes []openapi.Route) {
+	view := []openapi.Parameter{
+		openapi.QueryParam("fields", "string", "Comma-separated fields to return, id is always included: "+strings.Join(Fields, ", ")),
+		openapi.QueryParam("include", "string", "Related resources to embed", "user"),
+	}
+	for i := range routes {
+		route := &routes[i]
+		switch route.Method + " " + route.Path {
+		case http.MethodGet + " /books/":
+			route.Response = []dtos.BookResponse{}
+			route.Meta = dtos.CollectionMeta{}
+		case http.MethodGet + " /books/:id":
+			route.Response = dtos.BookResponse{}
+		case http.MethodPost + " /books/", http.MethodPut + " /books/:id":
+		default:
+			continue
+		}
+		route.Params = append(route.Params, view...)
+		route.Links = true
+	}
+}
@@ -0,0 +1,249 @@
+package book
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+type exportFormat struct {
+	ContentType string
+	Extension   string
+}
+
+// ExportFormats maps the supported ?format= values to their download metadata.
+var ExportFormats = map[string]exportFormat{
+	"csv":    {ContentType: "text/csv", Extension: "csv"},
+	"ndjson": {ContentType: "application/x-ndjson", Extension: "ndjson"},
+	"xlsx":   {ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx"},
+	"marc":   {ContentType: "text/plain; charset=utf-8", Extension: "mrk"},
+}
+
+var exportColumns = []string{"id", "user_id", "title", "author", "publisher", "year", "average_rating", "review_count"}
+
+// bookExporter writes books one at a time so an export never has to hold
+// the whole catalog in memory.
+type bookExporter interface {
+	begin() error
+	write(book *models.Book) error
+	end() error
+}
+
+// ExportBooks streams every book matching filter to w in the given format.
+func (s *Service) ExportBooks(w io.Writer, filter *dtos.BookFilter, format string) error {
+	exporter, err := newBookExporter(w, format)
+	if err != nil {
+		return err
+	}
+
+	if err := exporter.begin(); err != nil {
+		return err
+	}
+	if err := s.EachBook(filter, 0, exporter.write); err != nil {
+		return err
+	}
+	return exporter.end()
+}
+
+// EachBook calls fn with every book matching filter in ID order, or the
+// first limit of them when limit is above 0, reading them through a
+// database cursor rather than all at once. It stops at the first error fn
+// returns and returns it.
+func (s *Service) EachBook(filter *dtos.BookFilter, limit int, fn func(book *models.Book) error) error {
+	query := applyBookFilter(s.db.Model(&models.Book{}), filter).Order("books.id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return fmt.Errorf("failed to query books: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book models.Book
+		if err := s.db.ScanRows(rows, &book); err != nil {
+			return fmt.Errorf("failed to read book: %w", err)
+		}
+		if err := fn(&book); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read books: %w", err)
+	}
+	return nil
+}
+
+func newBookExporter(w io.Writer, format string) (bookExporter, error) {
+	switch format {
+	case "csv":
+		return &csvExporter{w: csv.NewWriter(w)}, nil
+	case "ndjson":
+		return &ndjsonExporter{enc: json.NewEncoder(w)}, nil
+	case "xlsx":
+		return &xlsxExporter{zw: zip.NewWriter(w)}, nil
+	case "marc":
+		return &marcExporter{w: w}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+func exportRecord(book *models.Book) []string {
+	return []string{
+		strconv.FormatUint(uint64(book.Id), 10),
+		strconv.FormatUint(uint64(book.UserID), 10),
+		book.Title,
+		book.Author,
+		book.Publisher,
+		strconv.Itoa(book.Year),
+		strconv.FormatFloat(book.AverageRating, 'f', 2, 64),
+		strconv.Itoa(book.ReviewCount),
+	}
+}
+
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvExporter) begin() error {
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvExporter) write(book *models.Book) error {
+	return e.w.Write(exportRecord(book))
+}
+
+func (e *csvExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonExporter struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExporter) begin() error { return nil }
+
+func (e *ndjsonExporter) write(book *models.Book) error {
+	return e.enc.Encode(ToBookResponse(book))
+}
+
+func (e *ndjsonExporter) end() error { return nil }
+
+// xlsxExporter writes a minimal single-sheet workbook. The zip entries are
+// written in order and the sheet is streamed last, so rows go straight to
+// the response instead of being buffered by a spreadsheet library.
+type xlsxExporter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+}
+
+const xlsxNamespace = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+
+var xlsxParts = []struct {
+	Name    string
+	Content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="` + xlsxNamespace + `" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Books" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func (e *xlsxExporter) begin() error {
+	for _, part := range xlsxParts {
+		w, err := e.zw.Create(part.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, part.Content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := e.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	e.sheet = sheet
+
+	if _, err := io.WriteString(e.sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+`<worksheet xmlns="`+xlsxNamespace+`"><sheetData>`); err != nil {
+		return err
+	}
+	return e.writeRow(exportColumns, nil)
+}
+
+func (e *xlsxExporter) write(book *models.Book) error {
+	// id, user_id, year, average_rating and review_count are numeric cells
+	return e.writeRow(exportRecord(book), map[int]bool{0: true, 1: true, 5: true, 6: true, 7: true})
+}
+
+func (e *xlsxExporter) writeRow(values []string, numeric map[int]bool) error {
+	var b strings.Builder
+	b.WriteString("<row>")
+	for i, value := range values {
+		if numeric[i] {
+			b.WriteString("<c><v>")
+			b.WriteString(value)
+			b.WriteString("</v></c>")
+			continue
+		}
+		b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&b, []byte(value)); err != nil {
+			return err
+		}
+		b.WriteString("</t></is></c>")
+	}
+	b.WriteString("</row>")
+
+	_, err := io.WriteString(e.sheet, b.String())
+	return err
+}
+
+func (e *xlsxExporter) end() error {
+	if _, err := io.WriteString(e.sheet, "</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
+
+// marcExporter writes records in the MARCMaker mnemonic (.mrk) text format,
+// which library tools can convert to binary MARC 21.
+type marcExporter struct {
+	w io.Writer
+}
+
+var marcEscaper = strings.NewReplacer("$", "{dollar}", "\n", " ", "\r", " ")
+
+func (e *marcExporter) begin() error { return nil }
+
+func (e *marcExporter) write(book *models.Book) error {
+	_, err := fmt.Fprintf(e.w,
+		"=LDR  00000nam a2200000 a 4500\n"+
+			"=001  %d\n"+
+			"=100  1\\$a%s\n"+
+			"=245  10$a%s\n"+
+			"=264  \\1$b%s$c%d\n\n",
+		book.Id,
+		marcEscaper.Replace(book.Author),
+		marcEscaper.Replace(book.Title),
+		marcEscaper.Replace(book.Publisher),
+		book.Year,
+	)
+	return err
+}
+
+func (e *marcExporter) end() error { return nil }
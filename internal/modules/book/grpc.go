@@ -0,0 +1,145 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	bookapiv1 "github.com/rakibulbanna/go-fiber-postgres/proto/bookapi/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// GRPCServer serves bookapi.v1.BookService from the same Service as the
+// HTTP controller.
+type GRPCServer struct {
+	bookapiv1.UnimplementedBookServiceServer
+	service *Service
+}
+
+// maxListBooks caps the books streamed by ListBooks.
+const maxListBooks = 1000
+
+func NewGRPCServer(service *Service) *GRPCServer {
+	return &GRPCServer{service: service}
+}
+
+func (s *GRPCServer) ListBooks(req *bookapiv1.ListBooksRequest, stream bookapiv1.BookService_ListBooksServer) error {
+	filter := dtos.BookFilter{
+		Title:     req.GetTitle(),
+		Author:    req.GetAuthor(),
+		Publisher: req.GetPublisher(),
+		Year:      int(req.GetYear()),
+		UserID:    uint(req.GetUserId()),
+	}
+
+	limit := maxListBooks
+	if n := req.GetLimit(); n > 0 && n < maxListBooks {
+		limit = int(n)
+	}
+
+	err := s.service.WithContext(stream.Context()).EachBook(&filter, limit, func(book *models.Book) error {
+		return stream.Send(toProtoBook(book))
+	})
+	if err != nil {
+		// Send fails with the status the client already got
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		slog.ErrorContext(stream.Context(), "Streaming books failed", "error", err)
+		return status.Error(codes.Internal, "failed to fetch books")
+	}
+	return nil
+}
+
+func (s *GRPCServer) GetBook(ctx context.Context, req *bookapiv1.GetBookRequest) (*bookapiv1.Book, error) {
+	book, err := s.service.WithContext(ctx).GetBookByID(uint(req.GetId()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "failed to fetch book")
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *GRPCServer) CreateBook(ctx context.Context, req *bookapiv1.CreateBookRequest) (*bookapiv1.Book, error) {
+	if req.GetTitle() == "" || req.GetPublisher() == "" || req.GetAuthor() == "" || req.GetYear() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Title, author, publisher, and year are required")
+	}
+
+	user, ok := middleware.GRPCUserFrom(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "User not authenticated")
+	}
+
+	book, err := s.service.WithContext(ctx).CreateBook(user.ID, &dtos.CreateBookRequest{
+		Author:    req.GetAuthor(),
+		Title:     req.GetTitle(),
+		Publisher: req.GetPublisher(),
+		Year:      int(req.GetYear()),
+	})
+	if err != nil {
+		return nil, grpcError(ctx, err, "failed to create book")
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *GRPCServer) UpdateBook(ctx context.Context, req *bookapiv1.UpdateBookRequest) (*bookapiv1.Book, error) {
+	user, ok := middleware.GRPCUserFrom(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "User not authenticated")
+	}
+
+	book, err := s.service.WithContext(ctx).UpdateBook(uint(req.GetId()), user.ID, &dtos.UpdateBookRequest{
+		Author:    req.GetAuthor(),
+		Title:     req.GetTitle(),
+		Publisher: req.GetPublisher(),
+		Year:      int(req.GetYear()),
+	})
+	if err != nil {
+		return nil, grpcError(ctx, err, "failed to update book")
+	}
+	return toProtoBook(book), nil
+}
+
+func (s *GRPCServer) DeleteBook(ctx context.Context, req *bookapiv1.DeleteBookRequest) (*emptypb.Empty, error) {
+	user, ok := middleware.GRPCUserFrom(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "User not authenticated")
+	}
+
+	if err := s.service.WithContext(ctx).DeleteBook(uint(req.GetId()), user.ID); err != nil {
+		return nil, grpcError(ctx, err, "failed to delete book")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// grpcError maps the errors of the service to statuses. Other errors are
+// logged and reported as Internal with message, so their details stay on
+// the server.
+func grpcError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, ErrBookNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		slog.ErrorContext(ctx, "Book request failed", "error", err)
+		return status.Error(codes.Internal, message)
+	}
+}
+
+func toProtoBook(book *models.Book) *bookapiv1.Book {
+	return &bookapiv1.Book{
+		Id:            uint32(book.Id),
+		UserId:        uint32(book.UserID),
+		Author:        book.Author,
+		Title:         book.Title,
+		Publisher:     book.Publisher,
+		Year:          int32(book.Year),
+		AverageRating: book.AverageRating,
+		ReviewCount:   int32(book.ReviewCount),
+	}
+}
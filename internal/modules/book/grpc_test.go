@@ -0,0 +1,34 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{"not found", ErrBookNotFound, codes.NotFound, ErrBookNotFound.Error()},
+		{"wrapped not found", fmt.Errorf("loading: %w", ErrBookNotFound), codes.NotFound, "loading: book not found"},
+		{"forbidden", ErrForbidden, codes.PermissionDenied, ErrForbidden.Error()},
+		{"other", errors.New(`pq: relation "books" does not exist`), codes.Internal, "failed to update book"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := status.FromError(grpcError(context.Background(), tt.err, "failed to update book"))
+			if s.Code() != tt.wantCode || s.Message() != tt.wantMessage {
+				t.Errorf("status %v %q, want %v %q", s.Code(), s.Message(), tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package book
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/outbox"
+	"gorm.io/gorm"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	// ImportModeTransactional imports every row or none of them.
+	ImportModeTransactional = "transactional"
+	// ImportModeBestEffort imports every valid row and reports the rest.
+	ImportModeBestEffort = "best_effort"
+)
+
+// importRow is a parsed row along with the 1-based line it starts on in the
+// source file, header included for CSV, so errors point at the line users see.
+type importRow struct {
+	Row     int
+	Request dtos.CreateBookRequest
+}
+
+// parseImport reads books from r in the given format. Rows that cannot be
+// parsed are returned as errors rather than aborting the whole import.
+func parseImport(r io.Reader, format string) ([]importRow, []dtos.ImportRowError, error) {
+	switch format {
+	case ImportFormatCSV:
+		return parseImportCSV(r)
+	case ImportFormatNDJSON:
+		return parseImportNDJSON(r)
+	}
+	return nil, nil, fmt.Errorf("unsupported import format: %s", format)
+}
+
+func parseImportCSV(r io.Reader) ([]importRow, []dtos.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("missing CSV header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "author", "publisher", "year"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV header is missing the %s column", required)
+		}
+	}
+
+	var rows []importRow
+	var rowErrors []dtos.ImportRowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The reader goes on with the next line after a malformed one
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, errors.New("failed to read CSV body")
+			}
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: parseErr.StartLine, Error: "malformed CSV row"})
+			continue
+		}
+		// Quoted fields may span lines, so count from where the record starts
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		req := dtos.CreateBookRequest{
+			Title:     field("title"),
+			Author:    field("author"),
+			Publisher: field("publisher"),
+		}
+		if year := field("year"); year != "" {
+			req.Year, err = strconv.Atoi(year)
+			if err != nil {
+				rowErrors = append(rowErrors, dtos.ImportRowError{Row: line, Error: "year must be a number"})
+				continue
+			}
+		}
+
+		rows = append(rows, importRow{Row: line, Request: req})
+	}
+
+	return rows, rowErrors, nil
+}
+
+func parseImportNDJSON(r io.Reader) ([]importRow, []dtos.ImportRowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	var rowErrors []dtos.ImportRowError
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var req dtos.CreateBookRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			rowErrors = append(rowErrors, dtos.ImportRowError{Row: line, Error: "invalid JSON"})
+			continue
+		}
+		rows = append(rows, importRow{Row: line, Request: req})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, errors.New("failed to read NDJSON body")
+	}
+
+	return rows, rowErrors, nil
+}
+
+// validateCreateBookRequest applies the rules declared on dtos.CreateBookRequest.
+func validateCreateBookRequest(req *dtos.CreateBookRequest) error {
+	var missing []string
+	if req.Title == "" {
+		missing = append(missing, "title")
+	}
+	if req.Author == "" {
+		missing = append(missing, "author")
+	}
+	if req.Publisher == "" {
+		missing = append(missing, "publisher")
+	}
+	if req.Year == 0 {
+		missing = append(missing, "year")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s required", strings.Join(missing, ", "))
+	}
+	if req.Year < 1000 || req.Year > 9999 {
+		return errors.New("year must be between 1000 and 9999")
+	}
+	return nil
+}
+
+// ImportBooks validates rows and, unless dryRun is set, creates the valid ones
+// for userID. parseErrors from parseImport are folded into the report.
+func (s *Service) ImportBooks(userID uint, rows []importRow, parseErrors []dtos.ImportRowError, mode string, dryRun bool) (*dtos.ImportBooksResponse, error) {
+	report := &dtos.ImportBooksResponse{
+		DryRun:    dryRun,
+		Mode:      mode,
+		TotalRows: len(rows) + len(parseErrors),
+		Errors:    append([]dtos.ImportRowError{}, parseErrors...),
+	}
+
+	var valid []importRow
+	for _, row := range rows {
+		if err := validateCreateBookRequest(&row.Request); err != nil {
+			report.Errors = append(report.Errors, dtos.ImportRowError{Row: row.Row, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, row)
+	}
+	report.ValidRows = len(valid)
+
+	switch {
+	case dryRun:
+	case mode == ImportModeTransactional:
+		if len(report.Errors) > 0 {
+			break
+		}
+		books := make([]models.Book, 0, len(valid))
+		for _, row := range valid {
+			books = append(books, newBook(userID, &row.Request))
+		}
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.CreateInBatches(&books, 100).Error; err != nil {
+				return err
+			}
+			created := make([]events.Event, len(books))
+			for i := range books {
+				created[i] = BookEvent(events.BookCreated, &books[i])
+			}
+			return outbox.Write(tx, created...)
+		}); err != nil {
+			return nil, errors.New("failed to import books")
+		}
+		report.Imported = len(books)
+	default:
+		for _, row := range valid {
+			book := newBook(userID, &row.Request)
+			if err := s.db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Create(&book).Error; err != nil {
+					return err
+				}
+				return outbox.Write(tx, BookEvent(events.BookCreated, &book))
+			}); err != nil {
+				report.Errors = append(report.Errors, dtos.ImportRowError{Row: row.Row, Error: "failed to create book"})
+				continue
+			}
+			report.Imported++
+		}
+	}
+
+	metrics.BooksCreated.Add(float64(report.Imported))
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	report.Failed = len(report.Errors)
+	return report, nil
+}
+
+// writeImportReport writes the per-row errors of report as CSV.
+func writeImportReport(w io.Writer, report *dtos.ImportBooksResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"row", "error"}); err != nil {
+		return err
+	}
+	for _, rowErr := range report.Errors {
+		if err := writer.Write([]string{strconv.Itoa(rowErr.Row), rowErr.Error}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func newBook(userID uint, req *dtos.CreateBookRequest) models.Book {
+	return models.Book{
+		UserID:    userID,
+		Author:    req.Author,
+		Title:     req.Title,
+		Publisher: req.Publisher,
+		Year:      req.Year,
+	}
+}
@@ -0,0 +1,150 @@
+package book
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantRows   []int
+		wantTitles []string
+		wantErrors []dtos.ImportRowError
+	}{
+		{
+			name:       "one row per line",
+			body:       "title,author,publisher,year\nDune,Herbert,Chilton,1965\nEmma,Austen,Murray,1815\n",
+			wantRows:   []int{2, 3},
+			wantTitles: []string{"Dune", "Emma"},
+		},
+		{
+			name:       "columns in any order and case",
+			body:       "Year, Publisher, Author, Title\n1965, Chilton, Herbert, Dune\n",
+			wantRows:   []int{2},
+			wantTitles: []string{"Dune"},
+		},
+		{
+			name:       "quoted field spanning lines",
+			body:       "title,author,publisher,year\n\"Dune\nMessiah\",Herbert,Putnam,1969\nEmma,Austen,Murray,1815\n",
+			wantRows:   []int{2, 4},
+			wantTitles: []string{"Dune\nMessiah", "Emma"},
+		},
+		{
+			name:       "blank lines",
+			body:       "title,author,publisher,year\n\nDune,Herbert,Chilton,1965\n\nEmma,Austen,Murray,1815\n",
+			wantRows:   []int{3, 5},
+			wantTitles: []string{"Dune", "Emma"},
+		},
+		{
+			name:       "year not a number",
+			body:       "title,author,publisher,year\nDune,Herbert,Chilton,soon\nEmma,Austen,Murray,1815\n",
+			wantRows:   []int{3},
+			wantTitles: []string{"Emma"},
+			wantErrors: []dtos.ImportRowError{{Row: 2, Error: "year must be a number"}},
+		},
+		{
+			name:       "malformed row after a multi-line one",
+			body:       "title,author,publisher,year\n\"Dune\nMessiah\",Herbert,Putnam,1969\nEm\"ma,Austen,Murray,1815\nPersuasion,Austen,Murray,1817\n",
+			wantRows:   []int{2, 5},
+			wantTitles: []string{"Dune\nMessiah", "Persuasion"},
+			wantErrors: []dtos.ImportRowError{{Row: 4, Error: "malformed CSV row"}},
+		},
+		{
+			name:       "missing fields",
+			body:       "title,author,publisher,year\nDune\n",
+			wantRows:   []int{2},
+			wantTitles: []string{"Dune"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrors, err := parseImport(strings.NewReader(tt.body), ImportFormatCSV)
+			if err != nil {
+				t.Fatalf("parseImport: %v", err)
+			}
+
+			var gotRows []int
+			var gotTitles []string
+			for _, row := range rows {
+				gotRows = append(gotRows, row.Row)
+				gotTitles = append(gotTitles, row.Request.Title)
+			}
+			if !reflect.DeepEqual(gotRows, tt.wantRows) || !reflect.DeepEqual(gotTitles, tt.wantTitles) {
+				t.Errorf("rows %v with titles %q, want %v with %q", gotRows, gotTitles, tt.wantRows, tt.wantTitles)
+			}
+			if !reflect.DeepEqual(rowErrors, tt.wantErrors) {
+				t.Errorf("errors %+v, want %+v", rowErrors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestParseImportHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "empty body", body: "", wantErr: "missing CSV header"},
+		{name: "missing column", body: "title,author,year\n", wantErr: "CSV header is missing the publisher column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseImport(strings.NewReader(tt.body), ImportFormatCSV)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseImportNDJSON(t *testing.T) {
+	body := "{\"title\":\"Dune\",\"year\":1965}\n\nnot json\n{\"title\":\"Emma\"}\n"
+	rows, rowErrors, err := parseImport(strings.NewReader(body), ImportFormatNDJSON)
+	if err != nil {
+		t.Fatalf("parseImport: %v", err)
+	}
+
+	if len(rows) != 2 || rows[0].Row != 1 || rows[0].Request.Year != 1965 || rows[1].Row != 4 {
+		t.Errorf("rows %+v, want Dune on line 1 and Emma on line 4", rows)
+	}
+	want := []dtos.ImportRowError{{Row: 3, Error: "invalid JSON"}}
+	if !reflect.DeepEqual(rowErrors, want) {
+		t.Errorf("errors %+v, want %+v", rowErrors, want)
+	}
+}
+
+func TestValidateCreateBookRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     dtos.CreateBookRequest
+		wantErr string
+	}{
+		{name: "valid", req: dtos.CreateBookRequest{Title: "Dune", Author: "Herbert", Publisher: "Chilton", Year: 1965}},
+		{name: "all missing", req: dtos.CreateBookRequest{}, wantErr: "title, author, publisher, year required"},
+		{name: "year out of range", req: dtos.CreateBookRequest{Title: "Dune", Author: "Herbert", Publisher: "Chilton", Year: 65}, wantErr: "year must be between 1000 and 9999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCreateBookRequest(&tt.req)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("got error %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,26 @@
+package book
+
+import (
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+// ToBookResponse maps a book to its API representation, the one place this
+// happens. The owner is included when it was loaded along with the book,
+// without their email, which is not for every reader of the book.
+func ToBookResponse(book *models.Book) *dtos.BookResponse {
+	response := &dtos.BookResponse{
+		ID:            book.Id,
+		UserID:        book.UserID,
+		Author:        book.Author,
+		Title:         book.Title,
+		Publisher:     book.Publisher,
+		Year:          book.Year,
+		AverageRating: book.AverageRating,
+		ReviewCount:   book.ReviewCount,
+	}
+	if book.User.ID != 0 {
+		response.User = &dtos.UserResponse{ID: book.User.ID, Name: book.User.Name}
+	}
+	return response
+}
@@ -0,0 +1,42 @@
+package book
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+func TestToBookResponse(t *testing.T) {
+	book := models.Book{
+		Id: 7, UserID: 3, Author: "Herbert", Title: "Dune", Publisher: "Chilton", Year: 1965,
+		AverageRating: 4.5, ReviewCount: 2,
+	}
+	want := dtos.BookResponse{
+		ID: 7, UserID: 3, Author: "Herbert", Title: "Dune", Publisher: "Chilton", Year: 1965,
+		AverageRating: 4.5, ReviewCount: 2,
+	}
+
+	withOwner := book
+	withOwner.User = models.User{ID: 3, Email: "frank@example.com", Name: "Frank", Password: "hash"}
+	wantWithOwner := want
+	wantWithOwner.User = &dtos.UserResponse{ID: 3, Name: "Frank"}
+
+	tests := []struct {
+		name string
+		book models.Book
+		want dtos.BookResponse
+	}{
+		{name: "without owner", book: book, want: want},
+		{name: "with owner", book: withOwner, want: wantWithOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToBookResponse(&tt.book); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -5,17 +5,27 @@ import (
 	"github.com/rakibulbanna/go-fiber-postgres/middleware"
 )
 
-func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware) {
+// SetupRoutes registers the routes of the given API version. From version 2
+// on, books are returned in a dtos.Envelope with sparse fieldsets.
+func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware, version int) {
+	getBooks, getBook := controller.GetBooks, controller.GetBook
+	createBook, updateBook := controller.CreateBook, controller.UpdateBook
+	if version >= 2 {
+		getBooks, getBook = controller.GetBooksV2, controller.GetBookV2
+		createBook, updateBook = controller.CreateBookV2, controller.UpdateBookV2
+	}
+
 	books := router.Group("/books")
-	
+
 	// Public routes
-	books.Get("/", controller.GetBooks)
-	books.Get("/:id", controller.GetBook)
+	books.Get("/", authMiddleware.OptionalAuth, getBooks)
+	books.Get("/export", authMiddleware.RequireAuth, controller.ExportBooks)
+	books.Get("/:id", authMiddleware.OptionalAuth, getBook)
 
 	// Protected routes
 	protectedBooks := router.Group("/books", authMiddleware.RequireAuth)
-	protectedBooks.Post("/", controller.CreateBook)
-	protectedBooks.Put("/:id", controller.UpdateBook)
+	protectedBooks.Post("/", createBook)
+	protectedBooks.Post("/import", controller.ImportBooks)
+	protectedBooks.Put("/:id", updateBook)
 	protectedBooks.Delete("/:id", controller.DeleteBook)
 }
-
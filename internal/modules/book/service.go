@@ -1,22 +1,52 @@
 package book
 
 import (
+	"context"
 	"errors"
 
 	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
 	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/outbox"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/utils"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrBookNotFound = errors.New("book not found")
+	ErrForbidden    = errors.New("unauthorized: you can only change your own books")
+)
+
 type Service struct {
-	db *gorm.DB
+	db       *gorm.DB
+	replicas *storage.Replicas
+	ctx      context.Context
+}
+
+// NewService creates the book service. Listing and lookups by ID are served
+// from replicas, all other queries from db.
+func NewService(db *gorm.DB, replicas *storage.Replicas) *Service {
+	return &Service{db: db, replicas: replicas, ctx: context.Background()}
 }
 
-func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+// WithContext returns a copy of the service whose queries run with ctx, and
+// whose reads follow the session in ctx to the primary after it writes.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
 }
 
-func (s *Service) CreateBook(userID uint, req *dtos.CreateBookRequest) (*dtos.BookResponse, error) {
+// reader returns the connection for reads that tolerate replication lag.
+func (s *Service) reader() *gorm.DB {
+	return s.replicas.Reader(s.ctx).WithContext(s.ctx)
+}
+
+// CreateBook creates a book owned by userID and returns it with its owner.
+func (s *Service) CreateBook(userID uint, req *dtos.CreateBookRequest) (*models.Book, error) {
 	book := &models.Book{
 		UserID:    userID,
 		Author:    req.Author,
@@ -25,23 +55,22 @@ func (s *Service) CreateBook(userID uint, req *dtos.CreateBookRequest) (*dtos.Bo
 		Year:      req.Year,
 	}
 
-	if err := s.db.Create(book).Error; err != nil {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(book).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, BookEvent(events.BookCreated, book))
+	}); err != nil {
 		return nil, errors.New("failed to create book")
 	}
+	metrics.BooksCreated.Inc()
 
-	return &dtos.BookResponse{
-		ID:        book.Id,
-		UserID:    book.UserID,
-		Author:    book.Author,
-		Title:     book.Title,
-		Publisher: book.Publisher,
-		Year:      book.Year,
-	}, nil
+	return s.withOwner(book)
 }
 
-func (s *Service) GetAllBooks() ([]models.Book, error) {
+func (s *Service) GetAllBooks(filter *dtos.BookFilter) ([]models.Book, error) {
 	var books []models.Book
-	if err := s.db.Joins("User").Find(&books).Error; err != nil {
+	if err := applyBookFilter(s.reader().Joins("User"), filter).Find(&books).Error; err != nil {
 		return nil, errors.New("failed to fetch books")
 	}
 	return books, nil
@@ -49,21 +78,39 @@ func (s *Service) GetAllBooks() ([]models.Book, error) {
 
 func (s *Service) GetBookByID(id uint) (*models.Book, error) {
 	var book models.Book
-	if err := s.db.Joins("User").First(&book, id).Error; err != nil {
-		return nil, errors.New("book not found")
+	if err := s.reader().Joins("User").First(&book, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, errors.New("failed to fetch book")
 	}
 	return &book, nil
 }
 
-func (s *Service) UpdateBook(id uint, userID uint, req *dtos.UpdateBookRequest) (*dtos.BookResponse, error) {
+// GetBooksByUserIDs returns the books of the given users, without their
+// owners, in one query.
+func (s *Service) GetBooksByUserIDs(userIDs []uint) ([]models.Book, error) {
+	var books []models.Book
+	if err := s.reader().Where("user_id IN ?", userIDs).Order("id").Find(&books).Error; err != nil {
+		return nil, errors.New("failed to fetch books")
+	}
+	return books, nil
+}
+
+// UpdateBook applies the non-empty fields of req to one of userID's books
+// and returns it with its owner.
+func (s *Service) UpdateBook(id uint, userID uint, req *dtos.UpdateBookRequest) (*models.Book, error) {
 	var book models.Book
 	if err := s.db.First(&book, id).Error; err != nil {
-		return nil, errors.New("book not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, errors.New("failed to update book")
 	}
 
 	// Check if user owns the book
 	if book.UserID != userID {
-		return nil, errors.New("unauthorized: you can only update your own books")
+		return nil, ErrForbidden
 	}
 
 	if req.Author != "" {
@@ -79,34 +126,79 @@ func (s *Service) UpdateBook(id uint, userID uint, req *dtos.UpdateBookRequest)
 		book.Year = req.Year
 	}
 
-	if err := s.db.Save(&book).Error; err != nil {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Only the editable fields, the rating columns belong to reviews
+		if err := tx.Model(&book).Select("author", "title", "publisher", "year").Updates(&book).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, BookEvent(events.BookUpdated, &book))
+	}); err != nil {
 		return nil, errors.New("failed to update book")
 	}
 
-	return &dtos.BookResponse{
-		ID:        book.Id,
-		UserID:    book.UserID,
-		Author:    book.Author,
-		Title:     book.Title,
-		Publisher: book.Publisher,
-		Year:      book.Year,
-	}, nil
+	return s.withOwner(&book)
+}
+
+// withOwner loads the owner of a book just written, from the primary.
+func (s *Service) withOwner(book *models.Book) (*models.Book, error) {
+	if err := s.db.First(&book.User, book.UserID).Error; err != nil {
+		return nil, errors.New("book owner not found")
+	}
+	return book, nil
 }
 
 func (s *Service) DeleteBook(id uint, userID uint) error {
 	var book models.Book
 	if err := s.db.First(&book, id).Error; err != nil {
-		return errors.New("book not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBookNotFound
+		}
+		return errors.New("failed to delete book")
 	}
 
 	// Check if user owns the book
 	if book.UserID != userID {
-		return errors.New("unauthorized: you can only delete your own books")
+		return ErrForbidden
 	}
 
-	if err := s.db.Delete(&book).Error; err != nil {
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&book).Error; err != nil {
+			return err
+		}
+		return outbox.Write(tx, BookEvent(events.BookDeleted, &book))
+	}); err != nil {
 		return errors.New("failed to delete book")
 	}
 	return nil
 }
 
+// BookEvent describes a change to book, to be written to the outbox along
+// with it. The event carries the book without its owner, whose ID it
+// includes.
+func BookEvent(eventType string, book *models.Book) events.Event {
+	data := ToBookResponse(book)
+	data.User = nil
+	return events.New(eventType, book.Id, book.UserID, data)
+}
+
+func applyBookFilter(db *gorm.DB, filter *dtos.BookFilter) *gorm.DB {
+	if filter == nil {
+		return db
+	}
+	if filter.Title != "" {
+		db = db.Where(`books.title ILIKE ? ESCAPE '\'`, utils.LikeContains(filter.Title))
+	}
+	if filter.Author != "" {
+		db = db.Where(`books.author ILIKE ? ESCAPE '\'`, utils.LikeContains(filter.Author))
+	}
+	if filter.Publisher != "" {
+		db = db.Where(`books.publisher ILIKE ? ESCAPE '\'`, utils.LikeContains(filter.Publisher))
+	}
+	if filter.Year != 0 {
+		db = db.Where("books.year = ?", filter.Year)
+	}
+	if filter.UserID != 0 {
+		db = db.Where("books.user_id = ?", filter.UserID)
+	}
+	return db
+}
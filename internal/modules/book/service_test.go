@@ -0,0 +1,162 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+	"gorm.io/gorm"
+)
+
+func TestApplyBookFilter(t *testing.T) {
+	db := storagetest.Open(t).Session(&gorm.Session{DryRun: true})
+
+	tests := []struct {
+		name     string
+		filter   *dtos.BookFilter
+		wantVars []interface{}
+	}{
+		{name: "no filter", filter: nil},
+		{name: "plain", filter: &dtos.BookFilter{Title: "dune"}, wantVars: []interface{}{"%dune%"}},
+		{name: "wildcards", filter: &dtos.BookFilter{Title: "100%", Author: "a_b"}, wantVars: []interface{}{`%100\%%`, `%a\_b%`}},
+		{name: "backslash", filter: &dtos.BookFilter{Publisher: `C:\`}, wantVars: []interface{}{`%C:\\%`}},
+		{name: "exact fields", filter: &dtos.BookFilter{Year: 1965, UserID: 3}, wantVars: []interface{}{1965, uint(3)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := applyBookFilter(db.Model(&models.Book{}), tt.filter).Find(&[]models.Book{}).Statement
+			if !reflect.DeepEqual(stmt.Vars, tt.wantVars) && len(stmt.Vars)+len(tt.wantVars) > 0 {
+				t.Errorf("vars %q, want %q", stmt.Vars, tt.wantVars)
+			}
+			sql := stmt.SQL.String()
+			if strings.Count(sql, "ILIKE") != strings.Count(sql, `ESCAPE '\'`) {
+				t.Errorf("ILIKE without ESCAPE in %s", sql)
+			}
+		})
+	}
+}
+
+// newTestService returns a service over a fresh database holding books
+// owned by user 1, with IDs 1 to books. User 2 owns none.
+func newTestService(t *testing.T, books int) *Service {
+	t.Helper()
+
+	db := storagetest.Open(t, &models.User{}, &models.Book{}, &models.OutboxEvent{})
+	for _, email := range []string{"owner@example.com", "other@example.com"} {
+		if err := db.Create(&models.User{Email: email, Password: "x", Name: "User"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < books; i++ {
+		if err := db.Create(&models.Book{UserID: 1, Author: "Herbert", Title: "Dune", Publisher: "Chilton", Year: 1965}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replicas, err := storage.NewReplicas(context.Background(), db, storage.Config{}, nil, storage.ReplicaOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewService(db, replicas)
+}
+
+func TestBookErrors(t *testing.T) {
+	service := newTestService(t, 1)
+
+	update := func(id, userID uint) error {
+		_, err := service.UpdateBook(id, userID, &dtos.UpdateBookRequest{Title: "Dune Messiah"})
+		return err
+	}
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{"get", func() error { _, err := service.GetBookByID(1); return err }, nil},
+		{"get missing", func() error { _, err := service.GetBookByID(2); return err }, ErrBookNotFound},
+		{"update", func() error { return update(1, 1) }, nil},
+		{"update missing", func() error { return update(2, 1) }, ErrBookNotFound},
+		{"update other's", func() error { return update(1, 2) }, ErrForbidden},
+		{"delete missing", func() error { return service.DeleteBook(2, 1) }, ErrBookNotFound},
+		{"delete other's", func() error { return service.DeleteBook(1, 2) }, ErrForbidden},
+		{"delete", func() error { return service.DeleteBook(1, 1) }, nil},
+		{"get deleted", func() error { _, err := service.GetBookByID(1); return err }, ErrBookNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEachBookLimit(t *testing.T) {
+	service := newTestService(t, 3)
+
+	tests := []struct {
+		limit   int
+		wantIDs []uint
+	}{
+		{limit: 0, wantIDs: []uint{1, 2, 3}},
+		{limit: 2, wantIDs: []uint{1, 2}},
+		{limit: 5, wantIDs: []uint{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		var ids []uint
+		if err := service.EachBook(&dtos.BookFilter{}, tt.limit, func(book *models.Book) error {
+			ids = append(ids, book.Id)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ids, tt.wantIDs) {
+			t.Errorf("limit %d: IDs %v, want %v", tt.limit, ids, tt.wantIDs)
+		}
+	}
+}
+
+// TestUpdateBookKeepsRatings checks that an update leaves the rating
+// columns alone when a review changes them after the book was read.
+func TestUpdateBookKeepsRatings(t *testing.T) {
+	service := newTestService(t, 1)
+	if err := service.db.Callback().Update().Before("gorm:update").Register("test:review", func(db *gorm.DB) {
+		if db.Statement.Table == "books" {
+			db.Session(&gorm.Session{NewDB: true}).Exec("UPDATE books SET average_rating = 4.5, review_count = 2 WHERE id = 1")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		req  dtos.UpdateBookRequest
+	}{
+		{"title", dtos.UpdateBookRequest{Title: "Dune Messiah"}},
+		{"every field", dtos.UpdateBookRequest{Author: "Frank Herbert", Title: "Children of Dune", Publisher: "Putnam", Year: 1976}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.UpdateBook(1, 1, &tt.req); err != nil {
+				t.Fatal(err)
+			}
+			var book models.Book
+			if err := service.db.First(&book, 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if book.Title != tt.req.Title || book.AverageRating != 4.5 || book.ReviewCount != 2 {
+				t.Errorf("title %q, average %v over %d reviews, want %q, 4.5 over 2", book.Title, book.AverageRating, book.ReviewCount, tt.req.Title)
+			}
+		})
+	}
+}
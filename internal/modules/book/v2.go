@@ -0,0 +1,163 @@
+package book
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/utils"
+)
+
+// Version 2 of the book endpoints returns every book as a
+// dtos.BookResponse inside a dtos.Envelope, with sparse fieldsets.
+
+// Fields lists the book fields ?fields= may select. The owner is requested
+// with ?include=user instead.
+var Fields = []string{"id", "user_id", "author", "title", "publisher", "year", "average_rating", "review_count"}
+
+func (c *Controller) GetBooksV2(ctx *fiber.Ctx) error {
+	view, ok := parseView(ctx)
+	if !ok {
+		return nil
+	}
+	return c.getBooks(ctx, func(books []models.Book) error {
+		data := make([]interface{}, len(books))
+		for i := range books {
+			item, err := view.render(&books[i])
+			if err != nil {
+				return err
+			}
+			data[i] = item
+		}
+		return ctx.Status(fiber.StatusOK).JSON(dtos.Envelope{
+			Data:  data,
+			Meta:  dtos.CollectionMeta{Count: len(books)},
+			Links: map[string]string{"self": ctx.OriginalURL()},
+		})
+	})
+}
+
+func (c *Controller) GetBookV2(ctx *fiber.Ctx) error {
+	view, ok := parseView(ctx)
+	if !ok {
+		return nil
+	}
+	return c.getBook(ctx, func(book *models.Book) error {
+		return view.respond(ctx, fiber.StatusOK, "", book)
+	})
+}
+
+func (c *Controller) CreateBookV2(ctx *fiber.Ctx) error {
+	view, ok := parseView(ctx)
+	if !ok {
+		return nil
+	}
+	return c.createBook(ctx, func(book *models.Book) error {
+		return view.respond(ctx, fiber.StatusCreated, "Book created successfully", book)
+	})
+}
+
+func (c *Controller) UpdateBookV2(ctx *fiber.Ctx) error {
+	view, ok := parseView(ctx)
+	if !ok {
+		return nil
+	}
+	return c.updateBook(ctx, func(book *models.Book) error {
+		return view.respond(ctx, fiber.StatusOK, "Book updated successfully", book)
+	})
+}
+
+// view is the representation of books a request asked for.
+type view struct {
+	// fields is nil for all fields
+	fields      map[string]bool
+	includeUser bool
+}
+
+// parseView reads ?fields= and ?include=. It reports false once it has
+// written an error response.
+func parseView(ctx *fiber.Ctx) (*view, bool) {
+	v := &view{}
+	if fields := ctx.Query("fields"); fields != "" {
+		// The ID is always included so results can be told apart
+		v.fields = map[string]bool{"id": true}
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if !isField(field) {
+				ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Unknown field " + strconv.Quote(field) + ", must be one of " + strings.Join(Fields, ", "),
+				})
+				return nil, false
+			}
+			v.fields[field] = true
+		}
+	}
+
+	if include := ctx.Query("include"); include != "" {
+		for _, relation := range strings.Split(include, ",") {
+			if strings.TrimSpace(relation) != "user" {
+				ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Unknown include " + strconv.Quote(relation) + ", must be user",
+				})
+				return nil, false
+			}
+			v.includeUser = true
+		}
+	}
+	return v, true
+}
+
+func isField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// render maps book through ToBookResponse and applies the view.
+func (v *view) render(book *models.Book) (interface{}, error) {
+	response := ToBookResponse(book)
+	if !v.includeUser {
+		response.User = nil
+	}
+	if v.fields == nil {
+		return response, nil
+	}
+
+	keys := make(map[string]bool, len(v.fields)+1)
+	for field := range v.fields {
+		keys[field] = true
+	}
+	keys["user"] = v.includeUser
+	object, err := utils.SelectFields(response, keys)
+	if err != nil {
+		return nil, errors.New("failed to encode book")
+	}
+	return object, nil
+}
+
+func (v *view) respond(ctx *fiber.Ctx, status int, message string, book *models.Book) error {
+	data, err := v.render(book)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// The prefix is set by middleware.APIVersion; v2 is where views are served
+	prefix, ok := ctx.Locals("apiPrefix").(string)
+	if !ok {
+		prefix = "/api/v2"
+	}
+	self := prefix + "/books/" + strconv.FormatUint(uint64(book.Id), 10)
+	return ctx.Status(status).JSON(dtos.Envelope{
+		Data:    data,
+		Links:   map[string]string{"self": self, "reviews": self + "/reviews"},
+		Message: message,
+	})
+}
@@ -0,0 +1,134 @@
+package book
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+func TestView(t *testing.T) {
+	book := &models.Book{
+		Id: 7, UserID: 3, Author: "Herbert", Title: "Dune", Publisher: "Chilton", Year: 1965,
+		AverageRating: 4.5, ReviewCount: 2,
+		User: models.User{ID: 3, Email: "frank@example.com", Name: "Frank"},
+	}
+
+	app := fiber.New()
+	app.Get("/books/7", func(ctx *fiber.Ctx) error {
+		view, ok := parseView(ctx)
+		if !ok {
+			return nil
+		}
+		rendered, err := view.render(book)
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(rendered)
+	})
+
+	all := []string{"author", "average_rating", "id", "publisher", "review_count", "title", "user_id", "year"}
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantKeys   []string
+	}{
+		{name: "all fields", query: "", wantStatus: http.StatusOK, wantKeys: all},
+		{name: "all fields with user", query: "?include=user", wantStatus: http.StatusOK, wantKeys: []string{"author", "average_rating", "id", "publisher", "review_count", "title", "user", "user_id", "year"}},
+		{name: "sparse", query: "?fields=title,year", wantStatus: http.StatusOK, wantKeys: []string{"id", "title", "year"}},
+		{name: "sparse with spaces", query: "?fields=title,%20author", wantStatus: http.StatusOK, wantKeys: []string{"author", "id", "title"}},
+		{name: "sparse with user", query: "?fields=title&include=user", wantStatus: http.StatusOK, wantKeys: []string{"id", "title", "user"}},
+		{name: "id only", query: "?fields=id", wantStatus: http.StatusOK, wantKeys: []string{"id"}},
+		{name: "unknown field", query: "?fields=title,isbn", wantStatus: http.StatusBadRequest},
+		{name: "user as field", query: "?fields=user", wantStatus: http.StatusBadRequest},
+		{name: "unknown include", query: "?include=reviews", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/books/7"+tt.query, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var object map[string]interface{}
+			if err := json.Unmarshal(body, &object); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if _, ok := object["error"]; !ok {
+					t.Errorf("body %s has no error", body)
+				}
+				return
+			}
+
+			keys := make([]string, 0, len(object))
+			for key := range object {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys %v, want %v", keys, tt.wantKeys)
+			}
+			if object["id"] != float64(7) {
+				t.Errorf("id %v, want 7", object["id"])
+			}
+		})
+	}
+}
+
+func TestViewRespondLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		wantSelf string
+	}{
+		{name: "prefix", prefix: "/api/v3", wantSelf: "/api/v3/books/7"},
+		{name: "no prefix", wantSelf: "/api/v2/books/7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/books/7", func(ctx *fiber.Ctx) error {
+				if tt.prefix != "" {
+					ctx.Locals("apiPrefix", tt.prefix)
+				}
+				view, ok := parseView(ctx)
+				if !ok {
+					return nil
+				}
+				return view.respond(ctx, http.StatusOK, "", &models.Book{Id: 7, Title: "Dune"})
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/books/7", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var body struct{ Links map[string]string }
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Links["self"] != tt.wantSelf || body.Links["reviews"] != tt.wantSelf+"/reviews" {
+				t.Errorf("links %v, want self %s", body.Links, tt.wantSelf)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/gofiber/fiber/v2"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// maxDepth bounds query nesting, users and books refer to each other.
+const maxDepth = 8
+
+type Controller struct {
+	schema *graphqlgo.Schema
+	books  *book.Service
+	users  *auth.Service
+}
+
+func NewController(books *book.Service, users *auth.Service) *Controller {
+	return &Controller{
+		schema: graphqlgo.MustParseSchema(schema, &resolver{books: books, users: users},
+			graphqlgo.UseStringDescriptions(),
+			graphqlgo.MaxDepth(maxDepth),
+		),
+		books: books,
+		users: users,
+	}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Execute runs a GraphQL request. Like other GraphQL servers it responds
+// 200 with the errors in the body once the request is well-formed.
+func (c *Controller) Execute(ctx *fiber.Ctx) error {
+	var req request
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Query == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Query is required",
+		})
+	}
+
+	execCtx := context.WithValue(ctx.UserContext(), loadersKey{}, newLoaders(c.users, c.books))
+	if userID, ok := ctx.Locals("userID").(uint); ok {
+		role, _ := ctx.Locals("userRole").(string)
+		execCtx = context.WithValue(execCtx, viewerKey{}, viewer{userID: userID, role: role})
+	}
+
+	response := c.schema.Exec(execCtx, req.Query, req.OperationName, req.Variables)
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+// loaders batch the lookups resolvers make per parent object, such as the
+// owner of every book in a list, into one query per field. They cache for
+// the duration of a request.
+type loaders struct {
+	users       *dataloader.Loader[uint, *models.User]
+	booksByUser *dataloader.Loader[uint, []models.Book]
+}
+
+type loadersKey struct{}
+
+func newLoaders(users *auth.Service, books *book.Service) *loaders {
+	return &loaders{
+		users: dataloader.NewBatchedLoader(func(ctx context.Context, ids []uint) []*dataloader.Result[*models.User] {
+			found, err := users.WithContext(ctx).FindUsersByIDs(ids)
+			byID := make(map[uint]*models.User, len(found))
+			for i := range found {
+				byID[found[i].ID] = &found[i]
+			}
+
+			results := make([]*dataloader.Result[*models.User], len(ids))
+			for i, id := range ids {
+				switch user := byID[id]; {
+				case err != nil:
+					results[i] = &dataloader.Result[*models.User]{Error: err}
+				case user == nil:
+					results[i] = &dataloader.Result[*models.User]{Error: errors.New("user not found")}
+				default:
+					results[i] = &dataloader.Result[*models.User]{Data: user}
+				}
+			}
+			return results
+		}),
+		booksByUser: dataloader.NewBatchedLoader(func(ctx context.Context, userIDs []uint) []*dataloader.Result[[]models.Book] {
+			found, err := books.WithContext(ctx).GetBooksByUserIDs(userIDs)
+			byUser := make(map[uint][]models.Book, len(userIDs))
+			for _, b := range found {
+				byUser[b.UserID] = append(byUser[b.UserID], b)
+			}
+
+			results := make([]*dataloader.Result[[]models.Book], len(userIDs))
+			for i, userID := range userIDs {
+				results[i] = &dataloader.Result[[]models.Book]{Data: byUser[userID], Error: err}
+			}
+			return results
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
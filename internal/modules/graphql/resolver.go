@@ -0,0 +1,300 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+// maxUsers caps the page size of the users query.
+const maxUsers = 100
+
+// resolver is the root of the schema, its methods resolve the fields of
+// Query and Mutation. Auth and ownership rules are those of the REST API,
+// enforced by the same services.
+type resolver struct {
+	books *book.Service
+	users *auth.Service
+}
+
+// viewer is the signed-in user of a request.
+type viewer struct {
+	userID uint
+	role   string
+}
+
+type viewerKey struct{}
+
+func viewerFrom(ctx context.Context) (viewer, bool) {
+	v, ok := ctx.Value(viewerKey{}).(viewer)
+	return v, ok
+}
+
+func requireViewer(ctx context.Context) (viewer, error) {
+	v, ok := viewerFrom(ctx)
+	if !ok {
+		return viewer{}, errors.New("User not authenticated")
+	}
+	return v, nil
+}
+
+func parseID(id graphqlgo.ID) (uint, error) {
+	n, err := strconv.ParseUint(string(id), 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid ID " + strconv.Quote(string(id)))
+	}
+	return uint(n), nil
+}
+
+func formatID(id uint) graphqlgo.ID {
+	return graphqlgo.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+func (r *resolver) Books(ctx context.Context, args struct {
+	Filter *struct {
+		Title     *string
+		Author    *string
+		Publisher *string
+		Year      *int32
+		UserID    *graphqlgo.ID
+	}
+}) ([]*bookResolver, error) {
+	var filter dtos.BookFilter
+	if f := args.Filter; f != nil {
+		filter.Title = deref(f.Title)
+		filter.Author = deref(f.Author)
+		filter.Publisher = deref(f.Publisher)
+		if f.Year != nil {
+			filter.Year = int(*f.Year)
+		}
+		if f.UserID != nil {
+			userID, err := parseID(*f.UserID)
+			if err != nil {
+				return nil, err
+			}
+			filter.UserID = userID
+		}
+	}
+
+	books, err := r.books.WithContext(ctx).GetAllBooks(&filter)
+	if err != nil {
+		return nil, err
+	}
+	return bookResolvers(books), nil
+}
+
+func (r *resolver) Book(ctx context.Context, args struct{ ID graphqlgo.ID }) (*bookResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.books.WithContext(ctx).GetBookByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &bookResolver{book: b}, nil
+}
+
+func (r *resolver) Users(ctx context.Context, args struct {
+	Search *string
+	Limit  int32
+	Offset int32
+}) ([]*userResolver, error) {
+	v, err := requireViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if v.role != models.RoleAdmin {
+		return nil, errors.New("Insufficient permissions")
+	}
+
+	filter := dtos.UserFilter{Search: deref(args.Search), Limit: maxUsers, Offset: int(args.Offset)}
+	if args.Limit > 0 && args.Limit < maxUsers {
+		filter.Limit = int(args.Limit)
+	}
+	users, _, err := r.users.WithContext(ctx).ListUsers(&filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*userResolver, len(users))
+	for i, u := range users {
+		resolvers[i] = &userResolver{user: dtos.UserResponse{ID: u.ID, Email: u.Email, Name: u.Name}}
+	}
+	return resolvers, nil
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphqlgo.ID }) (*userResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadUser(ctx, id)
+}
+
+func (r *resolver) Me(ctx context.Context) (*userResolver, error) {
+	v, ok := viewerFrom(ctx)
+	if !ok {
+		return nil, nil
+	}
+	return loadUser(ctx, v.userID)
+}
+
+func (r *resolver) CreateBook(ctx context.Context, args struct {
+	Input struct {
+		Author    string
+		Title     string
+		Publisher string
+		Year      int32
+	}
+}) (*bookResolver, error) {
+	v, err := requireViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dtos.CreateBookRequest{
+		Author:    args.Input.Author,
+		Title:     args.Input.Title,
+		Publisher: args.Input.Publisher,
+		Year:      int(args.Input.Year),
+	}
+	if req.Title == "" || req.Publisher == "" || req.Author == "" || req.Year == 0 {
+		return nil, errors.New("Title, author, publisher, and year are required")
+	}
+
+	b, err := r.books.WithContext(ctx).CreateBook(v.userID, &req)
+	if err != nil {
+		return nil, err
+	}
+	return &bookResolver{book: b}, nil
+}
+
+func (r *resolver) UpdateBook(ctx context.Context, args struct {
+	ID    graphqlgo.ID
+	Input struct {
+		Author    *string
+		Title     *string
+		Publisher *string
+		Year      *int32
+	}
+}) (*bookResolver, error) {
+	v, err := requireViewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dtos.UpdateBookRequest{
+		Author:    deref(args.Input.Author),
+		Title:     deref(args.Input.Title),
+		Publisher: deref(args.Input.Publisher),
+	}
+	if args.Input.Year != nil {
+		req.Year = int(*args.Input.Year)
+	}
+
+	b, err := r.books.WithContext(ctx).UpdateBook(id, v.userID, &req)
+	if err != nil {
+		return nil, err
+	}
+	return &bookResolver{book: b}, nil
+}
+
+func (r *resolver) DeleteBook(ctx context.Context, args struct{ ID graphqlgo.ID }) (bool, error) {
+	v, err := requireViewer(ctx)
+	if err != nil {
+		return false, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.books.WithContext(ctx).DeleteBook(id, v.userID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type bookResolver struct {
+	book *models.Book
+}
+
+func bookResolvers(books []models.Book) []*bookResolver {
+	resolvers := make([]*bookResolver, len(books))
+	for i := range books {
+		resolvers[i] = &bookResolver{book: &books[i]}
+	}
+	return resolvers
+}
+
+func (r *bookResolver) ID() graphqlgo.ID       { return formatID(r.book.Id) }
+func (r *bookResolver) Author() string         { return r.book.Author }
+func (r *bookResolver) Title() string          { return r.book.Title }
+func (r *bookResolver) Publisher() string      { return r.book.Publisher }
+func (r *bookResolver) Year() int32            { return int32(r.book.Year) }
+func (r *bookResolver) AverageRating() float64 { return r.book.AverageRating }
+func (r *bookResolver) ReviewCount() int32     { return int32(r.book.ReviewCount) }
+
+// User returns the owner when it was loaded along with the book, and
+// batches the lookup with those of the other books otherwise.
+func (r *bookResolver) User(ctx context.Context) (*userResolver, error) {
+	if owner := r.book.User; owner.ID != 0 {
+		return &userResolver{user: dtos.UserResponse{ID: owner.ID, Email: owner.Email, Name: owner.Name}}, nil
+	}
+	return loadUser(ctx, r.book.UserID)
+}
+
+type userResolver struct {
+	user dtos.UserResponse
+}
+
+func loadUser(ctx context.Context, id uint) (*userResolver, error) {
+	user, err := loadersFrom(ctx).users.Load(ctx, id)()
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{user: dtos.UserResponse{ID: user.ID, Email: user.Email, Name: user.Name}}, nil
+}
+
+func (r *userResolver) ID() graphqlgo.ID { return formatID(r.user.ID) }
+func (r *userResolver) Name() string     { return r.user.Name }
+
+// Email is only shown to the user themselves and to admins, it is null for
+// everyone else.
+func (r *userResolver) Email(ctx context.Context) *string {
+	if !canSeeEmail(ctx, r.user.ID) {
+		return nil
+	}
+	return &r.user.Email
+}
+
+func canSeeEmail(ctx context.Context, userID uint) bool {
+	v, ok := viewerFrom(ctx)
+	return ok && (v.userID == userID || v.role == models.RoleAdmin)
+}
+
+func (r *userResolver) Books(ctx context.Context) ([]*bookResolver, error) {
+	books, err := loadersFrom(ctx).booksByUser.Load(ctx, r.user.ID)()
+	if err != nil {
+		return nil, err
+	}
+	return bookResolvers(books), nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
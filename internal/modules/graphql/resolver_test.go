@@ -0,0 +1,115 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	"github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+)
+
+func TestUserEmail(t *testing.T) {
+	db := storagetest.Open(t, &models.User{}, &models.Book{})
+	owner := models.User{Email: "owner@example.com", Password: "x", Name: "Owner", Role: models.RoleUser}
+	other := models.User{Email: "other@example.com", Password: "x", Name: "Other", Role: models.RoleUser}
+	for _, user := range []*models.User{&owner, &other} {
+		if err := db.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&models.Book{UserID: owner.ID, Author: "Herbert", Title: "Dune", Publisher: "Chilton", Year: 1965}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	replicas, err := storage.NewReplicas(context.Background(), db, storage.Config{}, nil, storage.ReplicaOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewController(book.NewService(db, replicas), auth.NewService(db, "secret"))
+
+	// The viewer is given by the X-User-ID and X-User-Role headers
+	app := fiber.New()
+	app.Post("/graphql", func(ctx *fiber.Ctx) error {
+		if id := ctx.Get("X-User-ID"); id != "" {
+			userID, err := strconv.ParseUint(id, 10, 32)
+			if err != nil {
+				return err
+			}
+			ctx.Locals("userID", uint(userID))
+			ctx.Locals("userRole", ctx.Get("X-User-Role"))
+		}
+		return ctx.Next()
+	}, controller.Execute)
+
+	userQuery := `{ user(id: ` + string(formatID(owner.ID)) + `) { email } }`
+	booksQuery := `{ books { user { email } } }`
+	tests := []struct {
+		name   string
+		query  string
+		viewer uint
+		role   string
+		want   *string
+	}{
+		{name: "anonymous", query: userQuery, want: nil},
+		{name: "other user", query: userQuery, viewer: other.ID, role: models.RoleUser, want: nil},
+		{name: "same user", query: userQuery, viewer: owner.ID, role: models.RoleUser, want: &owner.Email},
+		{name: "admin", query: userQuery, viewer: other.ID, role: models.RoleAdmin, want: &owner.Email},
+		{name: "book owner anonymous", query: booksQuery, want: nil},
+		{name: "book owner other user", query: booksQuery, viewer: other.ID, role: models.RoleUser, want: nil},
+		{name: "book owner same user", query: booksQuery, viewer: owner.ID, role: models.RoleUser, want: &owner.Email},
+		{name: "book owner admin", query: booksQuery, viewer: other.ID, role: models.RoleAdmin, want: &owner.Email},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(request{Query: tt.query})
+			req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.viewer != 0 {
+				req.Header.Set("X-User-ID", string(formatID(tt.viewer)))
+				req.Header.Set("X-User-Role", tt.role)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var result struct {
+				Data struct {
+					User  *struct{ Email *string }
+					Books []struct{ User struct{ Email *string } }
+				}
+				Errors []struct{ Message string }
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Errors) > 0 {
+				t.Fatalf("errors = %v", result.Errors)
+			}
+
+			var got *string
+			switch {
+			case result.Data.User != nil:
+				got = result.Data.User.Email
+			case len(result.Data.Books) == 1:
+				got = result.Data.Books[0].User.Email
+			default:
+				t.Fatalf("unexpected data %+v", result.Data)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("email = %v, want %v", deref(got), deref(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package graphql
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+)
+
+// SetupRoutes registers /graphql. Anonymous requests may run queries,
+// mutations require a bearer token.
+func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware) {
+	router.Post("/graphql", authMiddleware.OptionalAuth, controller.Execute)
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rakibulbanna/go-fiber-postgres/migrations"
+	"gorm.io/gorm"
+)
+
+// HealthChecker is a dependency the readiness probe depends on. Register new
+// dependencies (caches, queues, ...) by passing another implementation to NewController.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// DatabaseChecker pings the Postgres connection pool.
+type DatabaseChecker struct {
+	db *gorm.DB
+}
+
+func NewDatabaseChecker(db *gorm.DB) *DatabaseChecker {
+	return &DatabaseChecker{db: db}
+}
+
+func (c *DatabaseChecker) Name() string {
+	return "database"
+}
+
+func (c *DatabaseChecker) Check(ctx context.Context) error {
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// MigrationChecker verifies the database is at the latest embedded migration,
+// according to the revision table Atlas maintains.
+type MigrationChecker struct {
+	db *gorm.DB
+}
+
+func NewMigrationChecker(db *gorm.DB) *MigrationChecker {
+	return &MigrationChecker{db: db}
+}
+
+func (c *MigrationChecker) Name() string {
+	return "migrations"
+}
+
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	expected, err := migrations.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	db := c.db.WithContext(ctx)
+
+	var exists bool
+	if err := db.Raw("SELECT to_regclass('atlas_schema_revisions.atlas_schema_revisions') IS NOT NULL").Scan(&exists).Error; err != nil {
+		return err
+	}
+	if !exists {
+		if expected == "" {
+			return nil
+		}
+		return fmt.Errorf("no migrations applied, expected version %s", expected)
+	}
+
+	var revision struct {
+		Version string
+		Applied int
+		Total   int
+		Error   *string
+	}
+	if err := db.Raw(`SELECT version, applied, total, error FROM atlas_schema_revisions.atlas_schema_revisions
+		WHERE version NOT LIKE '.%' ORDER BY version DESC LIMIT 1`).Scan(&revision).Error; err != nil {
+		return err
+	}
+
+	if revision.Error != nil && *revision.Error != "" {
+		return fmt.Errorf("migration %s failed: %s", revision.Version, *revision.Error)
+	}
+	if revision.Applied < revision.Total {
+		return fmt.Errorf("migration %s partially applied (%d/%d statements)", revision.Version, revision.Applied, revision.Total)
+	}
+	if expected != "" && revision.Version != expected {
+		return fmt.Errorf("database at version %q, expected %s", revision.Version, expected)
+	}
+	return nil
+}
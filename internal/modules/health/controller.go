@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// checkTimeout bounds how long a single dependency check may take.
+const checkTimeout = 2 * time.Second
+
+type Controller struct {
+	ready    atomic.Bool
+	checkers []HealthChecker
+}
+
+func NewController(checkers ...HealthChecker) *Controller {
+	return &Controller{checkers: checkers}
+}
+
+type checkResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// SetReady flips the readiness probe, e.g. to false while the server drains on shutdown.
+func (c *Controller) SetReady(ready bool) {
+	c.ready.Store(ready)
+}
+
+func (c *Controller) Liveness(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+func (c *Controller) Readiness(ctx *fiber.Ctx) error {
+	if !c.ready.Load() {
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status": "draining",
+		})
+	}
+
+	results := c.runChecks(ctx.UserContext())
+
+	status, code := "ok", fiber.StatusOK
+	for _, result := range results {
+		if result.Status != "ok" {
+			status, code = "unavailable", fiber.StatusServiceUnavailable
+			break
+		}
+	}
+
+	return ctx.Status(code).JSON(fiber.Map{
+		"status": status,
+		"checks": results,
+	})
+}
+
+// runChecks runs every checker concurrently, each bounded by checkTimeout.
+func (c *Controller) runChecks(parent context.Context) map[string]checkResult {
+	results := make(map[string]checkResult, len(c.checkers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, checker := range c.checkers {
+		wg.Add(1)
+		go func(checker HealthChecker) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(parent, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := checker.Check(ctx)
+			result := checkResult{
+				Status:    "ok",
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			// The probe is public, the cause only goes to the log
+			if err != nil {
+				result.Status = "unavailable"
+				slog.WarnContext(parent, "Readiness check failed", "check", checker.Name(), "error", err)
+			}
+
+			mu.Lock()
+			results[checker.Name()] = result
+			mu.Unlock()
+		}(checker)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type stubChecker struct {
+	name string
+	err  error
+}
+
+func (c stubChecker) Name() string                { return c.name }
+func (c stubChecker) Check(context.Context) error { return c.err }
+
+func TestReadiness(t *testing.T) {
+	secret := errors.New(`dial tcp db.internal:5432: password authentication failed for user "app"`)
+
+	tests := []struct {
+		name       string
+		checkers   []HealthChecker
+		ready      bool
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			name:       "healthy",
+			checkers:   []HealthChecker{stubChecker{name: "database"}, stubChecker{name: "migrations"}},
+			ready:      true,
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+			wantChecks: map[string]string{"database": "ok", "migrations": "ok"},
+		},
+		{
+			name:       "failing check",
+			checkers:   []HealthChecker{stubChecker{name: "database", err: secret}, stubChecker{name: "migrations"}},
+			ready:      true,
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"database": "unavailable", "migrations": "ok"},
+		},
+		{
+			name:       "draining",
+			checkers:   []HealthChecker{stubChecker{name: "database"}},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "draining",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewController(tt.checkers...)
+			controller.SetReady(tt.ready)
+			app := fiber.New()
+			app.Get("/readyz", controller.Readiness)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(body), "db.internal") {
+				t.Errorf("body leaks the check error: %s", body)
+			}
+
+			var result struct {
+				Status string
+				Checks map[string]struct{ Status string }
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				t.Fatal(err)
+			}
+			checks := make(map[string]string)
+			for name, check := range result.Checks {
+				checks[name] = check.Status
+			}
+			if len(checks) == 0 {
+				checks = nil
+			}
+			if resp.StatusCode != tt.wantCode || result.Status != tt.wantStatus || !reflect.DeepEqual(checks, tt.wantChecks) {
+				t.Errorf("got %d %s %v, want %d %s %v", resp.StatusCode, result.Status, checks, tt.wantCode, tt.wantStatus, tt.wantChecks)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package health
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupRoutes(router fiber.Router, controller *Controller) {
+	router.Get("/healthz", controller.Liveness)
+	router.Get("/readyz", controller.Readiness)
+}
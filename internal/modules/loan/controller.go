@@ -0,0 +1,199 @@
+package loan
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+func (c *Controller) RequestLoan(ctx *fiber.Ctx) error {
+	var req dtos.CreateLoanRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.BookID == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Book ID is required",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	loan, err := c.service.WithContext(ctx.UserContext()).RequestLoan(userID, &req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Loan requested successfully",
+		"data":    loan,
+	})
+}
+
+func (c *Controller) GetLoans(ctx *fiber.Ctx) error {
+	role := ctx.Query("role")
+	if role != "" && role != "owner" && role != "borrower" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role must be owner or borrower",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	loans, err := c.service.WithContext(ctx.UserContext()).GetLoans(userID, role, ctx.Query("status"))
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": loans,
+	})
+}
+
+func (c *Controller) GetLoan(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	loan, err := c.service.WithContext(ctx.UserContext()).GetLoanByID(uint(id), userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": loan,
+	})
+}
+
+func (c *Controller) ApproveLoan(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var req dtos.ApproveLoanRequest
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	loan, err := c.service.WithContext(ctx.UserContext()).ApproveLoan(uint(id), userID, &req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Loan approved successfully",
+		"data":    loan,
+	})
+}
+
+func (c *Controller) DeclineLoan(ctx *fiber.Ctx) error {
+	return c.handleAction(ctx, (*Service).DeclineLoan, "Loan declined successfully")
+}
+
+func (c *Controller) CancelLoan(ctx *fiber.Ctx) error {
+	return c.handleAction(ctx, (*Service).CancelLoan, "Loan request cancelled successfully")
+}
+
+func (c *Controller) ConfirmReturn(ctx *fiber.Ctx) error {
+	return c.handleAction(ctx, (*Service).ConfirmReturn, "Loan return confirmed successfully")
+}
+
+// handleAction serves the loan transitions that only take an optional note.
+func (c *Controller) handleAction(ctx *fiber.Ctx, action func(s *Service, id uint, userID uint, note string) (*dtos.LoanResponse, error), message string) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var req dtos.LoanActionRequest
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	loan, err := action(c.service.WithContext(ctx.UserContext()), uint(id), userID, req.Note)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    loan,
+	})
+}
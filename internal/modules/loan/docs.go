@@ -0,0 +1,39 @@
+package loan
+
+import (
+	"net/http"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// Docs describes the routes registered by SetupRoutes for the OpenAPI document.
+func Docs() openapi.Module {
+	action := func(path, summary string) openapi.Route {
+		return openapi.Route{Method: http.MethodPost, Path: path, Summary: summary, Auth: openapi.Authenticated, Request: dtos.LoanActionRequest{}, OptionalBody: true, Response: dtos.LoanResponse{}}
+	}
+
+	return openapi.Module{
+		Tag:         "Loans",
+		Description: "Borrowing books between users",
+		Routes: []openapi.Route{
+			{Method: http.MethodPost, Path: "/loans/", Summary: "Request a loan", Auth: openapi.Authenticated, Request: dtos.CreateLoanRequest{}, Status: http.StatusCreated, Response: dtos.LoanResponse{}},
+			{
+				Method: http.MethodGet, Path: "/loans/", Summary: "List your loans", Auth: openapi.Authenticated,
+				Params: []openapi.Parameter{
+					openapi.QueryParam("role", "string", "Only loans where you are the owner or the borrower", "owner", "borrower"),
+					openapi.QueryParam("status", "string", "Only loans in this status",
+						models.LoanStatusRequested, models.LoanStatusApproved, models.LoanStatusDeclined,
+						models.LoanStatusCancelled, models.LoanStatusReturned, models.LoanStatusOverdue),
+				},
+				Response: []dtos.LoanResponse{},
+			},
+			{Method: http.MethodGet, Path: "/loans/:id", Summary: "Get a loan with its history", Auth: openapi.Authenticated, Response: dtos.LoanResponse{}},
+			{Method: http.MethodPost, Path: "/loans/:id/approve", Summary: "Approve a loan request", Auth: openapi.Authenticated, Request: dtos.ApproveLoanRequest{}, OptionalBody: true, Response: dtos.LoanResponse{}},
+			action("/loans/:id/decline", "Decline a loan request"),
+			action("/loans/:id/cancel", "Withdraw your loan request"),
+			action("/loans/:id/return", "Confirm a lent book came back"),
+		},
+	}
+}
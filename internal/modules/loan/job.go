@@ -0,0 +1,29 @@
+package loan
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunOverdueJob periodically marks loans past their due date as overdue until ctx is cancelled.
+func (s *Service) RunOverdueJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			marked, err := s.MarkOverdueLoans()
+			if err != nil {
+				slog.Error("Overdue loan check failed", "error", err)
+				continue
+			}
+			if marked > 0 {
+				slog.Info("Marked loans as overdue", "count", marked)
+			}
+		}
+	}
+}
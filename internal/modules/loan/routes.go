@@ -0,0 +1,18 @@
+package loan
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+)
+
+func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware) {
+	loans := router.Group("/loans", authMiddleware.RequireAuth)
+
+	loans.Post("/", controller.RequestLoan)
+	loans.Get("/", controller.GetLoans)
+	loans.Get("/:id", controller.GetLoan)
+	loans.Post("/:id/approve", controller.ApproveLoan)
+	loans.Post("/:id/decline", controller.DeclineLoan)
+	loans.Post("/:id/cancel", controller.CancelLoan)
+	loans.Post("/:id/return", controller.ConfirmReturn)
+}
@@ -0,0 +1,312 @@
+package loan
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	bookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrActiveLoan is returned when the borrower already has an active loan of
+// the book.
+var ErrActiveLoan = errors.New("you already have an active loan request for this book")
+
+// DefaultLoanPeriod is used when neither the borrower nor the owner picks a due date.
+const DefaultLoanPeriod = 14 * 24 * time.Hour
+
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func (s *Service) RequestLoan(borrowerID uint, req *dtos.CreateLoanRequest) (*dtos.LoanResponse, error) {
+	var loan models.Loan
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var book models.Book
+		if err := tx.First(&book, req.BookID).Error; err != nil {
+			return errors.New("book not found")
+		}
+
+		if book.UserID == borrowerID {
+			return errors.New("you cannot borrow your own book")
+		}
+
+		if req.DueDate != nil && !req.DueDate.After(time.Now()) {
+			return errors.New("due date must be in the future")
+		}
+
+		loan = models.Loan{
+			BookID:     book.Id,
+			OwnerID:    book.UserID,
+			BorrowerID: borrowerID,
+			Status:     models.LoanStatusRequested,
+			Message:    req.Message,
+			DueDate:    req.DueDate,
+		}
+		// One active loan per borrower and book, enforced by
+		// idx_loans_active_borrower so concurrent requests cannot both insert
+		if err := tx.Create(&loan).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrActiveLoan
+			}
+			return errors.New("failed to create loan request")
+		}
+
+		return recordEvent(tx, &loan, &borrowerID, "", req.Message)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toLoanResponse(&loan), nil
+}
+
+func (s *Service) GetLoans(userID uint, role string, status string) ([]dtos.LoanResponse, error) {
+	query := s.db.Preload("Book").Preload("Owner").Preload("Borrower")
+
+	switch role {
+	case "owner":
+		query = query.Where("owner_id = ?", userID)
+	case "borrower":
+		query = query.Where("borrower_id = ?", userID)
+	default:
+		query = query.Where("owner_id = ? OR borrower_id = ?", userID, userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var loans []models.Loan
+	if err := query.Order("created_at DESC").Find(&loans).Error; err != nil {
+		return nil, errors.New("failed to fetch loans")
+	}
+
+	responses := make([]dtos.LoanResponse, 0, len(loans))
+	for i := range loans {
+		responses = append(responses, *toLoanResponse(&loans[i]))
+	}
+	return responses, nil
+}
+
+func (s *Service) GetLoanByID(id uint, userID uint) (*dtos.LoanResponse, error) {
+	var loan models.Loan
+	if err := s.db.Preload("Book").Preload("Owner").Preload("Borrower").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&loan, id).Error; err != nil {
+		return nil, errors.New("loan not found")
+	}
+
+	// History is visible to both parties and nobody else
+	if loan.OwnerID != userID && loan.BorrowerID != userID {
+		return nil, errors.New("loan not found")
+	}
+
+	return toLoanResponse(&loan), nil
+}
+
+func (s *Service) ApproveLoan(id uint, ownerID uint, req *dtos.ApproveLoanRequest) (*dtos.LoanResponse, error) {
+	return s.transition(id, ownerID, models.LoanStatusApproved, req.Note, func(tx *gorm.DB, loan *models.Loan) error {
+		if loan.OwnerID != ownerID {
+			return errors.New("unauthorized: only the owner can approve a loan")
+		}
+
+		// Only one copy to lend, so only one loan can be out at a time. The
+		// book row lock makes concurrent approvals of its loans take turns,
+		// so the second one sees the first
+		var book models.Book
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&book, loan.BookID).Error; err != nil {
+			return errors.New("book not found")
+		}
+
+		var lent int64
+		if err := tx.Model(&models.Loan{}).
+			Where("book_id = ? AND id <> ? AND status IN ?", loan.BookID, loan.ID, []string{models.LoanStatusApproved, models.LoanStatusOverdue}).
+			Count(&lent).Error; err != nil {
+			return errors.New("failed to approve loan")
+		}
+		if lent > 0 {
+			return errors.New("book is already lent out")
+		}
+
+		now := time.Now()
+		dueDate := now.Add(DefaultLoanPeriod)
+		if req.DueDate != nil {
+			dueDate = *req.DueDate
+		} else if loan.DueDate != nil {
+			dueDate = *loan.DueDate
+		}
+		if !dueDate.After(now) {
+			return errors.New("due date must be in the future")
+		}
+
+		loan.ApprovedAt = &now
+		loan.DueDate = &dueDate
+		return nil
+	})
+}
+
+func (s *Service) DeclineLoan(id uint, ownerID uint, note string) (*dtos.LoanResponse, error) {
+	return s.transition(id, ownerID, models.LoanStatusDeclined, note, func(tx *gorm.DB, loan *models.Loan) error {
+		if loan.OwnerID != ownerID {
+			return errors.New("unauthorized: only the owner can decline a loan")
+		}
+		return nil
+	})
+}
+
+func (s *Service) CancelLoan(id uint, borrowerID uint, note string) (*dtos.LoanResponse, error) {
+	return s.transition(id, borrowerID, models.LoanStatusCancelled, note, func(tx *gorm.DB, loan *models.Loan) error {
+		if loan.BorrowerID != borrowerID {
+			return errors.New("unauthorized: only the borrower can cancel a loan request")
+		}
+		return nil
+	})
+}
+
+func (s *Service) ConfirmReturn(id uint, ownerID uint, note string) (*dtos.LoanResponse, error) {
+	return s.transition(id, ownerID, models.LoanStatusReturned, note, func(tx *gorm.DB, loan *models.Loan) error {
+		if loan.OwnerID != ownerID {
+			return errors.New("unauthorized: only the owner can confirm a return")
+		}
+
+		now := time.Now()
+		loan.ReturnedAt = &now
+		return nil
+	})
+}
+
+// MarkOverdueLoans flags every approved loan whose due date has passed and
+// returns how many loans were updated.
+func (s *Service) MarkOverdueLoans() (int64, error) {
+	var marked int64
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var loans []models.Loan
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND due_date < ?", models.LoanStatusApproved, time.Now()).
+			Find(&loans).Error; err != nil {
+			return errors.New("failed to fetch overdue loans")
+		}
+
+		for i := range loans {
+			loan := &loans[i]
+			from := loan.Status
+			loan.Status = models.LoanStatusOverdue
+			if err := tx.Model(loan).Update("status", loan.Status).Error; err != nil {
+				return errors.New("failed to mark loan as overdue")
+			}
+			if err := recordEvent(tx, loan, nil, from, "due date passed"); err != nil {
+				return err
+			}
+			marked++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return marked, nil
+}
+
+// transition loads the loan under a row lock, lets apply check permissions and
+// set status specific fields, then moves it to the given status and records the change.
+func (s *Service) transition(id uint, actorID uint, to string, note string, apply func(tx *gorm.DB, loan *models.Loan) error) (*dtos.LoanResponse, error) {
+	var loan models.Loan
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, id).Error; err != nil {
+			return errors.New("loan not found")
+		}
+
+		if loan.OwnerID != actorID && loan.BorrowerID != actorID {
+			return errors.New("loan not found")
+		}
+
+		if err := checkTransition(loan.Status, to); err != nil {
+			return err
+		}
+
+		if err := apply(tx, &loan); err != nil {
+			return err
+		}
+
+		from := loan.Status
+		loan.Status = to
+		if err := tx.Omit(clause.Associations).Save(&loan).Error; err != nil {
+			return errors.New("failed to update loan")
+		}
+
+		return recordEvent(tx, &loan, &actorID, from, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toLoanResponse(&loan), nil
+}
+
+func recordEvent(tx *gorm.DB, loan *models.Loan, actorID *uint, from string, note string) error {
+	event := &models.LoanEvent{
+		LoanID:     loan.ID,
+		ActorID:    actorID,
+		FromStatus: from,
+		ToStatus:   loan.Status,
+		Note:       note,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return errors.New("failed to record loan history")
+	}
+	return nil
+}
+
+func toLoanResponse(loan *models.Loan) *dtos.LoanResponse {
+	response := &dtos.LoanResponse{
+		ID:         loan.ID,
+		BookID:     loan.BookID,
+		OwnerID:    loan.OwnerID,
+		BorrowerID: loan.BorrowerID,
+		Status:     loan.Status,
+		Message:    loan.Message,
+		ApprovedAt: loan.ApprovedAt,
+		DueDate:    loan.DueDate,
+		ReturnedAt: loan.ReturnedAt,
+		CreatedAt:  loan.CreatedAt,
+		UpdatedAt:  loan.UpdatedAt,
+	}
+	if loan.Book.Id != 0 {
+		response.Book = bookModule.ToBookResponse(&loan.Book)
+	}
+	if loan.Owner.ID != 0 {
+		response.Owner = &dtos.UserResponse{ID: loan.Owner.ID, Email: loan.Owner.Email, Name: loan.Owner.Name}
+	}
+	if loan.Borrower.ID != 0 {
+		response.Borrower = &dtos.UserResponse{ID: loan.Borrower.ID, Email: loan.Borrower.Email, Name: loan.Borrower.Name}
+	}
+	for _, event := range loan.Events {
+		response.Events = append(response.Events, dtos.LoanEventResponse{
+			ID:         event.ID,
+			ActorID:    event.ActorID,
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			Note:       event.Note,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,47 @@
+package loan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+)
+
+func TestRequestLoanActive(t *testing.T) {
+	tests := []struct {
+		// existing is the status of the borrower's earlier loan of the book
+		existing string
+		wantErr  error
+	}{
+		{models.LoanStatusRequested, ErrActiveLoan},
+		{models.LoanStatusApproved, ErrActiveLoan},
+		{models.LoanStatusOverdue, ErrActiveLoan},
+		{models.LoanStatusDeclined, nil},
+		{models.LoanStatusCancelled, nil},
+		{models.LoanStatusReturned, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.existing, func(t *testing.T) {
+			db := storagetest.Open(t, &models.User{}, &models.Book{}, &models.Loan{}, &models.LoanEvent{})
+			for _, email := range []string{"owner@example.com", "borrower@example.com"} {
+				if err := db.Create(&models.User{Email: email, Password: "x", Name: "User"}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := db.Create(&models.Book{UserID: 1, Title: "Dune", Publisher: "Chilton", Year: 1965}).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Create(&models.Loan{BookID: 1, OwnerID: 1, BorrowerID: 2, Status: tt.existing}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			service := NewService(db)
+			if _, err := service.RequestLoan(2, &dtos.CreateLoanRequest{BookID: 1}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
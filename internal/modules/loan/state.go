@@ -0,0 +1,31 @@
+package loan
+
+import (
+	"fmt"
+
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+// transitions lists, for each loan status, the statuses it may move to.
+// Declined, cancelled and returned loans are final.
+var transitions = map[string][]string{
+	models.LoanStatusRequested: {models.LoanStatusApproved, models.LoanStatusDeclined, models.LoanStatusCancelled},
+	models.LoanStatusApproved:  {models.LoanStatusReturned, models.LoanStatusOverdue},
+	models.LoanStatusOverdue:   {models.LoanStatusReturned},
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func checkTransition(from, to string) error {
+	if !canTransition(from, to) {
+		return fmt.Errorf("invalid loan transition from %s to %s", from, to)
+	}
+	return nil
+}
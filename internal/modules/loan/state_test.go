@@ -0,0 +1,48 @@
+package loan
+
+import (
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{models.LoanStatusRequested, models.LoanStatusApproved, true},
+		{models.LoanStatusRequested, models.LoanStatusDeclined, true},
+		{models.LoanStatusRequested, models.LoanStatusCancelled, true},
+		{models.LoanStatusRequested, models.LoanStatusReturned, false},
+		{models.LoanStatusRequested, models.LoanStatusOverdue, false},
+		{models.LoanStatusApproved, models.LoanStatusReturned, true},
+		{models.LoanStatusApproved, models.LoanStatusOverdue, true},
+		{models.LoanStatusApproved, models.LoanStatusCancelled, false},
+		{models.LoanStatusApproved, models.LoanStatusDeclined, false},
+		{models.LoanStatusApproved, models.LoanStatusApproved, false},
+		{models.LoanStatusOverdue, models.LoanStatusReturned, true},
+		{models.LoanStatusOverdue, models.LoanStatusApproved, false},
+		{models.LoanStatusDeclined, models.LoanStatusApproved, false},
+		{models.LoanStatusCancelled, models.LoanStatusRequested, false},
+		{models.LoanStatusReturned, models.LoanStatusApproved, false},
+		{"unknown", models.LoanStatusApproved, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"_to_"+tt.to, func(t *testing.T) {
+			err := checkTransition(tt.from, tt.to)
+			if ok := err == nil; ok != tt.ok {
+				t.Errorf("checkTransition(%q, %q) = %v, want allowed %v", tt.from, tt.to, err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFinalStatuses(t *testing.T) {
+	for _, status := range []string{models.LoanStatusDeclined, models.LoanStatusCancelled, models.LoanStatusReturned} {
+		if next := transitions[status]; len(next) > 0 {
+			t.Errorf("final status %s may move to %v", status, next)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package review
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+// errorStatus returns the HTTP status of a service error.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrBookNotFound), errors.Is(err, ErrReviewNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		return fiber.StatusForbidden
+	case errors.Is(err, ErrAlreadyReviewed):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (c *Controller) CreateReview(ctx *fiber.Ctx) error {
+	bookID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid book ID format",
+		})
+	}
+
+	var req dtos.CreateReviewRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Rating < 1 || req.Rating > 5 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rating must be between 1 and 5",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	review, err := c.service.WithContext(ctx.UserContext()).CreateReview(uint(bookID), userID, &req)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Review created successfully",
+		"data":    review,
+	})
+}
+
+func (c *Controller) GetReviews(ctx *fiber.Ctx) error {
+	bookID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid book ID format",
+		})
+	}
+
+	reviews, err := c.service.WithContext(ctx.UserContext()).GetReviews(uint(bookID))
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": reviews,
+	})
+}
+
+func (c *Controller) GetReview(ctx *fiber.Ctx) error {
+	bookID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid book ID format",
+		})
+	}
+
+	id, err := strconv.ParseUint(ctx.Params("reviewId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid review ID format",
+		})
+	}
+
+	review, err := c.service.WithContext(ctx.UserContext()).GetReviewByID(uint(bookID), uint(id))
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": review,
+	})
+}
+
+func (c *Controller) UpdateReview(ctx *fiber.Ctx) error {
+	bookID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid book ID format",
+		})
+	}
+
+	id, err := strconv.ParseUint(ctx.Params("reviewId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid review ID format",
+		})
+	}
+
+	var req dtos.UpdateReviewRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Rating != 0 && (req.Rating < 1 || req.Rating > 5) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rating must be between 1 and 5",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	review, err := c.service.WithContext(ctx.UserContext()).UpdateReview(uint(bookID), uint(id), userID, &req)
+	if err != nil {
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Review updated successfully",
+		"data":    review,
+	})
+}
+
+func (c *Controller) DeleteReview(ctx *fiber.Ctx) error {
+	bookID, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid book ID format",
+		})
+	}
+
+	id, err := strconv.ParseUint(ctx.Params("reviewId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid review ID format",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := c.service.WithContext(ctx.UserContext()).DeleteReview(uint(bookID), uint(id), userID); err != nil {
+		return ctx.Status(errorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Review deleted successfully",
+	})
+}
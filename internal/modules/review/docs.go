@@ -0,0 +1,23 @@
+package review
+
+import (
+	"net/http"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// Docs describes the routes registered by SetupRoutes for the OpenAPI document.
+func Docs() openapi.Module {
+	return openapi.Module{
+		Tag:         "Reviews",
+		Description: "Ratings and reviews of books",
+		Routes: []openapi.Route{
+			{Method: http.MethodGet, Path: "/books/:id/reviews/", Summary: "List reviews of a book", Auth: openapi.Authenticated, Response: []dtos.ReviewResponse{}},
+			{Method: http.MethodGet, Path: "/books/:id/reviews/:reviewId", Summary: "Get a review", Auth: openapi.Authenticated, Response: dtos.ReviewResponse{}},
+			{Method: http.MethodPost, Path: "/books/:id/reviews/", Summary: "Review a book", Auth: openapi.Authenticated, Request: dtos.CreateReviewRequest{}, Status: http.StatusCreated, Response: dtos.ReviewResponse{}},
+			{Method: http.MethodPut, Path: "/books/:id/reviews/:reviewId", Summary: "Update your review", Auth: openapi.Authenticated, Request: dtos.UpdateReviewRequest{}, Response: dtos.ReviewResponse{}},
+			{Method: http.MethodDelete, Path: "/books/:id/reviews/:reviewId", Summary: "Delete your review", Auth: openapi.Authenticated},
+		},
+	}
+}
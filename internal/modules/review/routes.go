@@ -0,0 +1,16 @@
+package review
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+)
+
+func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware) {
+	reviews := router.Group("/books/:id/reviews", authMiddleware.RequireAuth)
+
+	reviews.Get("/", controller.GetReviews)
+	reviews.Get("/:reviewId", controller.GetReview)
+	reviews.Post("/", controller.CreateReview)
+	reviews.Put("/:reviewId", controller.UpdateReview)
+	reviews.Delete("/:reviewId", controller.DeleteReview)
+}
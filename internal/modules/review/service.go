@@ -0,0 +1,195 @@
+package review
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBookNotFound    = errors.New("book not found")
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrAlreadyReviewed = errors.New("you have already reviewed this book")
+	ErrForbidden       = errors.New("unauthorized: you can only change your own reviews")
+)
+
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func (s *Service) CreateReview(bookID uint, userID uint, req *dtos.CreateReviewRequest) (*dtos.ReviewResponse, error) {
+	review := &models.Review{
+		UserID: userID,
+		BookID: bookID,
+		Rating: req.Rating,
+		Text:   req.Text,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var book models.Book
+		if err := tx.First(&book, bookID).Error; err != nil {
+			return ErrBookNotFound
+		}
+
+		// One review per user per book, enforced by idx_reviews_user_book so
+		// concurrent requests cannot both insert
+		if err := tx.Create(review).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrAlreadyReviewed
+			}
+			return errors.New("failed to create review")
+		}
+
+		// Fold the new rating into the running average
+		if err := tx.Model(&models.Book{}).Where("id = ?", bookID).Updates(map[string]interface{}{
+			"average_rating": gorm.Expr("(average_rating * review_count + ?) / (review_count + 1)", review.Rating),
+			"review_count":   gorm.Expr("review_count + 1"),
+		}).Error; err != nil {
+			return errors.New("failed to update book rating")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReviewResponse(review), nil
+}
+
+func (s *Service) GetReviews(bookID uint) ([]dtos.ReviewResponse, error) {
+	var book models.Book
+	if err := s.db.First(&book, bookID).Error; err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	var reviews []models.Review
+	if err := s.db.Joins("User").Where("reviews.book_id = ?", bookID).Order("reviews.created_at DESC").Find(&reviews).Error; err != nil {
+		return nil, errors.New("failed to fetch reviews")
+	}
+
+	responses := make([]dtos.ReviewResponse, 0, len(reviews))
+	for i := range reviews {
+		responses = append(responses, *toReviewResponse(&reviews[i]))
+	}
+	return responses, nil
+}
+
+func (s *Service) GetReviewByID(bookID uint, id uint) (*dtos.ReviewResponse, error) {
+	var review models.Review
+	if err := s.db.Joins("User").Where("reviews.book_id = ?", bookID).First(&review, id).Error; err != nil {
+		return nil, ErrReviewNotFound
+	}
+	return toReviewResponse(&review), nil
+}
+
+func (s *Service) UpdateReview(bookID uint, id uint, userID uint, req *dtos.UpdateReviewRequest) (*dtos.ReviewResponse, error) {
+	var review models.Review
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Locked so concurrent edits take turns and each swaps out the
+		// rating the other left
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).First(&review, id).Error; err != nil {
+			return ErrReviewNotFound
+		}
+
+		// Check if user owns the review
+		if review.UserID != userID {
+			return ErrForbidden
+		}
+
+		oldRating := review.Rating
+		if req.Rating != 0 {
+			review.Rating = req.Rating
+		}
+		if req.Text != "" {
+			review.Text = req.Text
+		}
+
+		if err := tx.Save(&review).Error; err != nil {
+			return errors.New("failed to update review")
+		}
+
+		// Swap the old rating for the new one without touching the count
+		if review.Rating != oldRating {
+			if err := tx.Model(&models.Book{}).Where("id = ? AND review_count > 0", bookID).Update(
+				"average_rating", gorm.Expr("(average_rating * review_count - ? + ?) / review_count", oldRating, review.Rating),
+			).Error; err != nil {
+				return errors.New("failed to update book rating")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReviewResponse(&review), nil
+}
+
+func (s *Service) DeleteReview(bookID uint, id uint, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Locked so that of two concurrent deletes, the second finds no
+		// review rather than taking the rating out again
+		var review models.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).First(&review, id).Error; err != nil {
+			return ErrReviewNotFound
+		}
+
+		// Check if user owns the review
+		if review.UserID != userID {
+			return ErrForbidden
+		}
+
+		result := tx.Delete(&review)
+		if result.Error != nil {
+			return errors.New("failed to delete review")
+		}
+		if result.RowsAffected == 0 {
+			return ErrReviewNotFound
+		}
+
+		// Take the rating back out of the running average
+		if err := tx.Model(&models.Book{}).Where("id = ? AND review_count > 0", bookID).Updates(map[string]interface{}{
+			"average_rating": gorm.Expr("CASE WHEN review_count <= 1 THEN 0 ELSE (average_rating * review_count - ?) / (review_count - 1) END", review.Rating),
+			"review_count":   gorm.Expr("review_count - 1"),
+		}).Error; err != nil {
+			return errors.New("failed to update book rating")
+		}
+		return nil
+	})
+}
+
+func toReviewResponse(review *models.Review) *dtos.ReviewResponse {
+	response := &dtos.ReviewResponse{
+		ID:        review.ID,
+		UserID:    review.UserID,
+		BookID:    review.BookID,
+		Rating:    review.Rating,
+		Text:      review.Text,
+		CreatedAt: review.CreatedAt,
+		UpdatedAt: review.UpdatedAt,
+	}
+	if review.User.ID != 0 {
+		// Emails are private, reviews show who wrote them by name
+		response.User = &dtos.UserResponse{
+			ID:   review.User.ID,
+			Name: review.User.Name,
+		}
+	}
+	return response
+}
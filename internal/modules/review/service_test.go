@@ -0,0 +1,199 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+)
+
+// newTestService returns a service over a fresh database holding a book and
+// the given number of users, whose IDs are 1 to users.
+func newTestService(t *testing.T, users int) (*Service, uint) {
+	t.Helper()
+
+	db := storagetest.Open(t, &models.User{}, &models.Book{}, &models.Review{})
+	for i := 1; i <= users; i++ {
+		user := models.User{Email: fmt.Sprintf("user%d@example.com", i), Password: "x", Name: "User"}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	book := models.Book{UserID: 1, Title: "Dune", Publisher: "Chilton", Year: 1965}
+	if err := db.Create(&book).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewService(db), book.Id
+}
+
+func TestRatingMaths(t *testing.T) {
+	tests := []struct {
+		name string
+		// ratings are created by users 1, 2, ... in turn
+		ratings []int
+		// update sets the rating of the review of user update[0] to update[1]
+		update []int
+		// delete deletes the review of that user
+		delete      uint
+		wantAverage float64
+		wantCount   int
+	}{
+		{name: "create first", ratings: []int{4}, wantAverage: 4, wantCount: 1},
+		{name: "create several", ratings: []int{5, 2, 2}, wantAverage: 3, wantCount: 3},
+		{name: "create fractional", ratings: []int{5, 4}, wantAverage: 4.5, wantCount: 2},
+		{name: "update only review", ratings: []int{4}, update: []int{1, 2}, wantAverage: 2, wantCount: 1},
+		{name: "update one of several", ratings: []int{5, 3}, update: []int{1, 1}, wantAverage: 2, wantCount: 2},
+		{name: "update to same rating", ratings: []int{5, 3}, update: []int{2, 3}, wantAverage: 4, wantCount: 2},
+		{name: "delete one of several", ratings: []int{5, 3, 1}, delete: 1, wantAverage: 2, wantCount: 2},
+		{name: "delete only review", ratings: []int{4}, delete: 1, wantAverage: 0, wantCount: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, bookID := newTestService(t, len(tt.ratings))
+
+			reviewIDs := make(map[uint]uint)
+			for i, rating := range tt.ratings {
+				userID := uint(i + 1)
+				review, err := service.CreateReview(bookID, userID, &dtos.CreateReviewRequest{Rating: rating})
+				if err != nil {
+					t.Fatalf("CreateReview: %v", err)
+				}
+				reviewIDs[userID] = review.ID
+			}
+			if tt.update != nil {
+				userID := uint(tt.update[0])
+				if _, err := service.UpdateReview(bookID, reviewIDs[userID], userID, &dtos.UpdateReviewRequest{Rating: tt.update[1]}); err != nil {
+					t.Fatalf("UpdateReview: %v", err)
+				}
+			}
+			if tt.delete != 0 {
+				if err := service.DeleteReview(bookID, reviewIDs[tt.delete], tt.delete); err != nil {
+					t.Fatalf("DeleteReview: %v", err)
+				}
+			}
+
+			var book models.Book
+			if err := service.db.First(&book, bookID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(book.AverageRating-tt.wantAverage) > 1e-9 || book.ReviewCount != tt.wantCount {
+				t.Errorf("average %v over %d reviews, want %v over %d",
+					book.AverageRating, book.ReviewCount, tt.wantAverage, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestReviewErrors(t *testing.T) {
+	service, bookID := newTestService(t, 2)
+	review, err := service.CreateReview(bookID, 1, &dtos.CreateReviewRequest{Rating: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{
+			name: "review twice",
+			call: func() error {
+				_, err := service.CreateReview(bookID, 1, &dtos.CreateReviewRequest{Rating: 5})
+				return err
+			},
+			want: ErrAlreadyReviewed,
+		},
+		{
+			name: "review missing book",
+			call: func() error {
+				_, err := service.CreateReview(bookID+1, 2, &dtos.CreateReviewRequest{Rating: 5})
+				return err
+			},
+			want: ErrBookNotFound,
+		},
+		{
+			name: "update review of another user",
+			call: func() error {
+				_, err := service.UpdateReview(bookID, review.ID, 2, &dtos.UpdateReviewRequest{Rating: 1})
+				return err
+			},
+			want: ErrForbidden,
+		},
+		{
+			name: "delete review of another user",
+			call: func() error { return service.DeleteReview(bookID, review.ID, 2) },
+			want: ErrForbidden,
+		},
+		{
+			name: "delete missing review",
+			call: func() error { return service.DeleteReview(bookID, review.ID+1, 1) },
+			want: ErrReviewNotFound,
+		},
+		{
+			name: "get review of another book",
+			call: func() error {
+				_, err := service.GetReviewByID(bookID+1, review.ID)
+				return err
+			},
+			want: ErrReviewNotFound,
+		},
+		{
+			name: "delete review",
+			call: func() error { return service.DeleteReview(bookID, review.ID, 1) },
+			want: nil,
+		},
+		{
+			name: "delete review twice",
+			call: func() error { return service.DeleteReview(bookID, review.ID, 1) },
+			want: ErrReviewNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewResponseUser(t *testing.T) {
+	service, bookID := newTestService(t, 1)
+	created, err := service.CreateReview(bookID, 1, &dtos.CreateReviewRequest{Rating: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		get  func() (*dtos.ReviewResponse, error)
+	}{
+		{"list", func() (*dtos.ReviewResponse, error) {
+			reviews, err := service.GetReviews(bookID)
+			if err != nil || len(reviews) != 1 {
+				return nil, fmt.Errorf("got %d reviews, error %v", len(reviews), err)
+			}
+			return &reviews[0], nil
+		}},
+		{"single", func() (*dtos.ReviewResponse, error) { return service.GetReviewByID(bookID, created.ID) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, err := tt.get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if review.User == nil || review.User.Name != "User" || review.User.Email != "" {
+				t.Errorf("user %+v, want the name without the email", review.User)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package shelf
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+type Controller struct {
+	service *Service
+}
+
+func NewController(service *Service) *Controller {
+	return &Controller{service: service}
+}
+
+// errorStatus returns 409 for the service errors reporting a conflict, and
+// status for the others.
+func errorStatus(err error, status int) int {
+	if errors.Is(err, ErrShelfExists) || errors.Is(err, ErrAlreadyOnShelf) {
+		return fiber.StatusConflict
+	}
+	return status
+}
+
+func (c *Controller) GetShelves(ctx *fiber.Ctx) error {
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	shelves, err := c.service.WithContext(ctx.UserContext()).GetShelves(userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": shelves,
+	})
+}
+
+func (c *Controller) CreateShelf(ctx *fiber.Ctx) error {
+	var req dtos.CreateShelfRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Name == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	shelf, err := c.service.WithContext(ctx.UserContext()).CreateShelf(userID, &req)
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Shelf created successfully",
+		"data":    shelf,
+	})
+}
+
+func (c *Controller) UpdateShelf(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var req dtos.UpdateShelfRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Name == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	shelf, err := c.service.WithContext(ctx.UserContext()).UpdateShelf(uint(id), userID, &req)
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Shelf updated successfully",
+		"data":    shelf,
+	})
+}
+
+func (c *Controller) DeleteShelf(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := c.service.WithContext(ctx.UserContext()).DeleteShelf(uint(id), userID); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Shelf deleted successfully",
+	})
+}
+
+func (c *Controller) GetShelfEntries(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	entries, err := c.service.WithContext(ctx.UserContext()).GetShelfEntries(uint(id), userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": entries,
+	})
+}
+
+func (c *Controller) AddEntry(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var req dtos.AddShelfEntryRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.BookID == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Book ID is required",
+		})
+	}
+
+	if req.Status != "" && !models.IsValidReadingStatus(req.Status) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Status must be one of want_to_read, reading, read",
+		})
+	}
+
+	if req.Progress < 0 || req.Progress > 100 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Progress must be between 0 and 100",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	entry, err := c.service.WithContext(ctx.UserContext()).AddEntry(uint(id), userID, &req)
+	if err != nil {
+		return ctx.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Book added to shelf successfully",
+		"data":    entry,
+	})
+}
+
+func (c *Controller) UpdateEntry(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("entryId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var req dtos.UpdateShelfEntryRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Status != "" && !models.IsValidReadingStatus(req.Status) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Status must be one of want_to_read, reading, read",
+		})
+	}
+
+	if req.Progress != nil && (*req.Progress < 0 || *req.Progress > 100) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Progress must be between 0 and 100",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	entry, err := c.service.WithContext(ctx.UserContext()).UpdateEntry(uint(id), userID, &req)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Shelf entry updated successfully",
+		"data":    entry,
+	})
+}
+
+func (c *Controller) MoveEntry(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("entryId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	var req dtos.MoveShelfEntryRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.ShelfID == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Shelf ID is required",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	entry, err := c.service.WithContext(ctx.UserContext()).MoveEntry(uint(id), userID, &req)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Book moved successfully",
+		"data":    entry,
+	})
+}
+
+func (c *Controller) RemoveEntry(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseUint(ctx.Params("entryId"), 10, 32)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid ID format",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := c.service.WithContext(ctx.UserContext()).RemoveEntry(uint(id), userID); err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Book removed from shelf successfully",
+	})
+}
+
+func (c *Controller) GetReadingStats(ctx *fiber.Ctx) error {
+	year := ctx.QueryInt("year", time.Now().Year())
+	if year < 1000 || year > 9999 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid year",
+		})
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := ctx.Locals("userID").(uint)
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	stats, err := c.service.WithContext(ctx.UserContext()).GetReadingStats(userID, year)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": stats,
+	})
+}
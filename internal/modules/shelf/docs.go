@@ -0,0 +1,40 @@
+package shelf
+
+import (
+	"net/http"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// Docs describes the routes registered by SetupRoutes for the OpenAPI document.
+func Docs() openapi.Module {
+	return openapi.Module{
+		Tag:         "Shelves",
+		Description: "Reading shelves and progress",
+		Routes: []openapi.Route{
+			{Method: http.MethodGet, Path: "/shelves/", Summary: "List your shelves", Auth: openapi.Authenticated, Response: []dtos.ShelfResponse{}},
+			{
+				Method: http.MethodPost, Path: "/shelves/", Summary: "Create a custom shelf", Auth: openapi.Authenticated,
+				Description: "The names of the default shelves are reserved, and a name already taken fails with 409.",
+				Request:     dtos.CreateShelfRequest{}, Status: http.StatusCreated, Response: dtos.ShelfResponse{},
+			},
+			{
+				Method: http.MethodGet, Path: "/shelves/stats", Summary: "Yearly reading statistics", Auth: openapi.Authenticated,
+				Params:   []openapi.Parameter{openapi.QueryParam("year", "integer", "Defaults to the current year")},
+				Response: dtos.ReadingStatsResponse{},
+			},
+			{Method: http.MethodPut, Path: "/shelves/entries/:entryId", Summary: "Update reading status or progress", Auth: openapi.Authenticated, Request: dtos.UpdateShelfEntryRequest{}, Response: dtos.ShelfEntryResponse{}},
+			{Method: http.MethodPost, Path: "/shelves/entries/:entryId/move", Summary: "Move a book to another shelf", Auth: openapi.Authenticated, Request: dtos.MoveShelfEntryRequest{}, Response: dtos.ShelfEntryResponse{}},
+			{Method: http.MethodDelete, Path: "/shelves/entries/:entryId", Summary: "Remove a book from its shelf", Auth: openapi.Authenticated},
+			{Method: http.MethodPut, Path: "/shelves/:id", Summary: "Rename a custom shelf", Auth: openapi.Authenticated, Request: dtos.UpdateShelfRequest{}, Response: dtos.ShelfResponse{}},
+			{Method: http.MethodDelete, Path: "/shelves/:id", Summary: "Delete a custom shelf and its entries", Auth: openapi.Authenticated},
+			{Method: http.MethodGet, Path: "/shelves/:id/books", Summary: "List books on a shelf", Auth: openapi.Authenticated, Response: []dtos.ShelfEntryResponse{}},
+			{
+				Method: http.MethodPost, Path: "/shelves/:id/books", Summary: "Add a book to a shelf", Auth: openapi.Authenticated,
+				Description: "A book sits on at most one of your shelves: adding one that is already shelved fails with 409, move it instead.",
+				Request:     dtos.AddShelfEntryRequest{}, Status: http.StatusCreated, Response: dtos.ShelfEntryResponse{},
+			},
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package shelf
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+)
+
+func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware) {
+	shelves := router.Group("/shelves", authMiddleware.RequireAuth)
+
+	shelves.Get("/", controller.GetShelves)
+	shelves.Post("/", controller.CreateShelf)
+	shelves.Get("/stats", controller.GetReadingStats)
+
+	// Entries are addressed directly so a book can be moved without knowing its shelf
+	shelves.Put("/entries/:entryId", controller.UpdateEntry)
+	shelves.Post("/entries/:entryId/move", controller.MoveEntry)
+	shelves.Delete("/entries/:entryId", controller.RemoveEntry)
+
+	shelves.Put("/:id", controller.UpdateShelf)
+	shelves.Delete("/:id", controller.DeleteShelf)
+	shelves.Get("/:id/books", controller.GetShelfEntries)
+	shelves.Post("/:id/books", controller.AddEntry)
+}
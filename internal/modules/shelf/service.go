@@ -0,0 +1,420 @@
+package shelf
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	bookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrShelfExists    = errors.New("shelf with this name already exists")
+	ErrReservedName   = errors.New("shelf name is reserved for a default shelf")
+	ErrAlreadyOnShelf = errors.New("book is already on one of your shelves")
+)
+
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	return &clone
+}
+
+func (s *Service) GetShelves(userID uint) ([]dtos.ShelfResponse, error) {
+	if err := s.ensureDefaultShelves(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	var shelves []dtos.ShelfResponse
+	if err := s.db.Model(&models.Shelf{}).
+		Select("shelves.id, shelves.name, shelves.is_default, shelves.status, COUNT(shelf_entries.id) AS entry_count").
+		Joins("LEFT JOIN shelf_entries ON shelf_entries.shelf_id = shelves.id").
+		Where("shelves.user_id = ?", userID).
+		Group("shelves.id").
+		Order("shelves.is_default DESC, shelves.id").
+		Scan(&shelves).Error; err != nil {
+		return nil, errors.New("failed to fetch shelves")
+	}
+	return shelves, nil
+}
+
+func (s *Service) CreateShelf(userID uint, req *dtos.CreateShelfRequest) (*dtos.ShelfResponse, error) {
+	if models.IsDefaultShelfName(req.Name) {
+		return nil, ErrReservedName
+	}
+	if err := s.ensureDefaultShelves(s.db, userID); err != nil {
+		return nil, err
+	}
+
+	shelf := &models.Shelf{
+		UserID: userID,
+		Name:   req.Name,
+	}
+	if err := s.db.Create(shelf).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrShelfExists
+		}
+		return nil, errors.New("failed to create shelf")
+	}
+
+	return &dtos.ShelfResponse{
+		ID:   shelf.ID,
+		Name: shelf.Name,
+	}, nil
+}
+
+func (s *Service) UpdateShelf(id uint, userID uint, req *dtos.UpdateShelfRequest) (*dtos.ShelfResponse, error) {
+	shelf, err := s.findShelf(s.db, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if shelf.IsDefault {
+		return nil, errors.New("default shelves cannot be renamed")
+	}
+	if models.IsDefaultShelfName(req.Name) {
+		return nil, ErrReservedName
+	}
+
+	shelf.Name = req.Name
+	if err := s.db.Save(shelf).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrShelfExists
+		}
+		return nil, errors.New("failed to update shelf")
+	}
+
+	return &dtos.ShelfResponse{
+		ID:   shelf.ID,
+		Name: shelf.Name,
+	}, nil
+}
+
+func (s *Service) DeleteShelf(id uint, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		shelf, err := s.findShelf(tx, id, userID)
+		if err != nil {
+			return err
+		}
+
+		if shelf.IsDefault {
+			return errors.New("default shelves cannot be deleted")
+		}
+
+		if err := tx.Where("shelf_id = ?", shelf.ID).Delete(&models.ShelfEntry{}).Error; err != nil {
+			return errors.New("failed to delete shelf entries")
+		}
+		if err := tx.Delete(shelf).Error; err != nil {
+			return errors.New("failed to delete shelf")
+		}
+		return nil
+	})
+}
+
+func (s *Service) GetShelfEntries(id uint, userID uint) ([]dtos.ShelfEntryResponse, error) {
+	if _, err := s.findShelf(s.db, id, userID); err != nil {
+		return nil, err
+	}
+
+	var entries []models.ShelfEntry
+	if err := s.db.Joins("Book").Where("shelf_entries.shelf_id = ?", id).Order("shelf_entries.updated_at DESC").Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to fetch shelf entries")
+	}
+
+	responses := make([]dtos.ShelfEntryResponse, 0, len(entries))
+	for i := range entries {
+		responses = append(responses, *toShelfEntryResponse(&entries[i]))
+	}
+	return responses, nil
+}
+
+func (s *Service) AddEntry(shelfID uint, userID uint, req *dtos.AddShelfEntryRequest) (*dtos.ShelfEntryResponse, error) {
+	var entry models.ShelfEntry
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		shelf, err := s.findShelf(tx, shelfID, userID)
+		if err != nil {
+			return err
+		}
+
+		var book models.Book
+		if err := tx.First(&book, req.BookID).Error; err != nil {
+			return errors.New("book not found")
+		}
+
+		status := req.Status
+		if shelf.IsDefault {
+			status = shelf.Status
+		}
+		if status == "" {
+			status = models.ReadingStatusWantToRead
+		}
+
+		entry = models.ShelfEntry{
+			UserID:   userID,
+			BookID:   book.Id,
+			ShelfID:  shelf.ID,
+			Progress: req.Progress,
+		}
+		applyStatus(&entry, status, time.Now())
+
+		// A book lives on exactly one of the user's shelves, enforced by
+		// idx_shelf_entries_user_book
+		if err := tx.Create(&entry).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrAlreadyOnShelf
+			}
+			return errors.New("failed to add book to shelf")
+		}
+		entry.Book = book
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toShelfEntryResponse(&entry), nil
+}
+
+func (s *Service) UpdateEntry(id uint, userID uint, req *dtos.UpdateShelfEntryRequest) (*dtos.ShelfEntryResponse, error) {
+	var entry models.ShelfEntry
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.findEntry(tx, &entry, id, userID); err != nil {
+			return err
+		}
+
+		if req.Progress != nil {
+			entry.Progress = *req.Progress
+		}
+		if req.Status != "" && req.Status != entry.Status {
+			applyStatus(&entry, req.Status, time.Now())
+
+			// Keep entries on default shelves in line with their status
+			var shelf models.Shelf
+			if err := tx.First(&shelf, entry.ShelfID).Error; err == nil && shelf.IsDefault {
+				target, err := s.findDefaultShelf(tx, userID, entry.Status)
+				if err != nil {
+					return err
+				}
+				entry.ShelfID = target.ID
+			}
+		}
+		if req.StartedAt != nil {
+			entry.StartedAt = req.StartedAt
+		}
+		if req.FinishedAt != nil {
+			entry.FinishedAt = req.FinishedAt
+		}
+
+		if entry.StartedAt != nil && entry.FinishedAt != nil && entry.FinishedAt.Before(*entry.StartedAt) {
+			return errors.New("finished date cannot be before started date")
+		}
+
+		if err := tx.Omit("Book", "User", "Shelf").Save(&entry).Error; err != nil {
+			return errors.New("failed to update shelf entry")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toShelfEntryResponse(&entry), nil
+}
+
+func (s *Service) MoveEntry(id uint, userID uint, req *dtos.MoveShelfEntryRequest) (*dtos.ShelfEntryResponse, error) {
+	var entry models.ShelfEntry
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.findEntry(tx, &entry, id, userID); err != nil {
+			return err
+		}
+
+		target, err := s.findShelf(tx, req.ShelfID, userID)
+		if err != nil {
+			return err
+		}
+
+		entry.ShelfID = target.ID
+		if target.IsDefault && target.Status != entry.Status {
+			applyStatus(&entry, target.Status, time.Now())
+		}
+
+		if err := tx.Omit("Book", "User", "Shelf").Save(&entry).Error; err != nil {
+			return errors.New("failed to move shelf entry")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toShelfEntryResponse(&entry), nil
+}
+
+func (s *Service) RemoveEntry(id uint, userID uint) error {
+	var entry models.ShelfEntry
+	if err := s.findEntry(s.db, &entry, id, userID); err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(&entry).Error; err != nil {
+		return errors.New("failed to remove book from shelf")
+	}
+	return nil
+}
+
+func (s *Service) GetReadingStats(userID uint, year int) (*dtos.ReadingStatsResponse, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	stats := &dtos.ReadingStatsResponse{Year: year}
+
+	entries := func() *gorm.DB {
+		return s.db.Model(&models.ShelfEntry{}).Where("user_id = ?", userID)
+	}
+
+	if err := entries().Where("started_at >= ? AND started_at < ?", start, end).Count(&stats.BooksStarted).Error; err != nil {
+		return nil, errors.New("failed to compute reading statistics")
+	}
+	if err := entries().Where("status = ? AND finished_at >= ? AND finished_at < ?", models.ReadingStatusRead, start, end).Count(&stats.BooksFinished).Error; err != nil {
+		return nil, errors.New("failed to compute reading statistics")
+	}
+	if err := entries().Where("status = ?", models.ReadingStatusReading).Count(&stats.CurrentlyReading).Error; err != nil {
+		return nil, errors.New("failed to compute reading statistics")
+	}
+	if err := entries().Where("status = ?", models.ReadingStatusWantToRead).Count(&stats.WantToRead).Error; err != nil {
+		return nil, errors.New("failed to compute reading statistics")
+	}
+
+	var avgDays *float64
+	if err := entries().
+		Select("AVG(EXTRACT(EPOCH FROM finished_at - started_at) / 86400)").
+		Where("status = ? AND started_at IS NOT NULL AND finished_at >= ? AND finished_at < ?", models.ReadingStatusRead, start, end).
+		Scan(&avgDays).Error; err != nil {
+		return nil, errors.New("failed to compute reading statistics")
+	}
+	if avgDays != nil {
+		stats.AverageDaysToRead = *avgDays
+	}
+
+	var monthly []struct {
+		Month int
+		Count int64
+	}
+	if err := entries().
+		Select("EXTRACT(MONTH FROM finished_at)::int AS month, COUNT(*) AS count").
+		Where("status = ? AND finished_at >= ? AND finished_at < ?", models.ReadingStatusRead, start, end).
+		Group("month").
+		Scan(&monthly).Error; err != nil {
+		return nil, errors.New("failed to compute reading statistics")
+	}
+	for _, m := range monthly {
+		if m.Month >= 1 && m.Month <= 12 {
+			stats.FinishedByMonth[m.Month-1] = m.Count
+		}
+	}
+
+	return stats, nil
+}
+
+func (s *Service) ensureDefaultShelves(tx *gorm.DB, userID uint) error {
+	var count int64
+	if err := tx.Model(&models.Shelf{}).Where("user_id = ? AND is_default = ?", userID, true).Count(&count).Error; err != nil {
+		return errors.New("failed to fetch shelves")
+	}
+	if count >= int64(len(models.DefaultShelves)) {
+		return nil
+	}
+
+	// Concurrent requests may both get here, idx_shelves_user_default keeps
+	// one default shelf per status
+	for _, def := range models.DefaultShelves {
+		shelf := models.Shelf{UserID: userID, Name: def.Name, IsDefault: true, Status: def.Status}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&shelf).Error; err != nil {
+			return errors.New("failed to create default shelves")
+		}
+	}
+	return nil
+}
+
+func (s *Service) findShelf(tx *gorm.DB, id uint, userID uint) (*models.Shelf, error) {
+	var shelf models.Shelf
+	if err := tx.Where("user_id = ?", userID).First(&shelf, id).Error; err != nil {
+		return nil, errors.New("shelf not found")
+	}
+	return &shelf, nil
+}
+
+func (s *Service) findDefaultShelf(tx *gorm.DB, userID uint, status string) (*models.Shelf, error) {
+	if err := s.ensureDefaultShelves(tx, userID); err != nil {
+		return nil, err
+	}
+
+	var shelf models.Shelf
+	if err := tx.Where("user_id = ? AND is_default = ? AND status = ?", userID, true, status).First(&shelf).Error; err != nil {
+		return nil, errors.New("shelf not found")
+	}
+	return &shelf, nil
+}
+
+func (s *Service) findEntry(tx *gorm.DB, entry *models.ShelfEntry, id uint, userID uint) error {
+	if err := tx.Joins("Book").Where("shelf_entries.user_id = ?", userID).First(entry, id).Error; err != nil {
+		return errors.New("shelf entry not found")
+	}
+	return nil
+}
+
+// applyStatus moves an entry to a new reading status, stamping started and
+// finished dates the first time the book enters the matching state.
+func applyStatus(entry *models.ShelfEntry, status string, now time.Time) {
+	entry.Status = status
+
+	switch status {
+	case models.ReadingStatusWantToRead:
+		entry.StartedAt = nil
+		entry.FinishedAt = nil
+	case models.ReadingStatusReading:
+		if entry.StartedAt == nil {
+			entry.StartedAt = &now
+		}
+		entry.FinishedAt = nil
+	case models.ReadingStatusRead:
+		if entry.StartedAt == nil {
+			entry.StartedAt = &now
+		}
+		if entry.FinishedAt == nil {
+			entry.FinishedAt = &now
+		}
+		entry.Progress = 100
+	}
+}
+
+func toShelfEntryResponse(entry *models.ShelfEntry) *dtos.ShelfEntryResponse {
+	response := &dtos.ShelfEntryResponse{
+		ID:         entry.ID,
+		ShelfID:    entry.ShelfID,
+		BookID:     entry.BookID,
+		Status:     entry.Status,
+		Progress:   entry.Progress,
+		StartedAt:  entry.StartedAt,
+		FinishedAt: entry.FinishedAt,
+	}
+	if entry.Book.Id != 0 {
+		response.Book = bookModule.ToBookResponse(&entry.Book)
+	}
+	return response
+}
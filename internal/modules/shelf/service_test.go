@@ -0,0 +1,156 @@
+package shelf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+)
+
+// newTestService returns a service over a fresh database holding user 1
+// and a book.
+func newTestService(t *testing.T) (*Service, uint) {
+	t.Helper()
+
+	db := storagetest.Open(t, &models.User{}, &models.Book{}, &models.Shelf{}, &models.ShelfEntry{})
+	if err := db.Create(&models.User{Email: "reader@example.com", Password: "x", Name: "Reader"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	book := models.Book{UserID: 1, Title: "Dune", Publisher: "Chilton", Year: 1965}
+	if err := db.Create(&book).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewService(db), book.Id
+}
+
+func TestDefaultShelves(t *testing.T) {
+	tests := []struct {
+		name string
+		// custom is a shelf the user had before getting default shelves
+		custom string
+	}{
+		{name: "new user"},
+		{name: "custom shelf named like a default one", custom: "Read"},
+		{name: "other custom shelf", custom: "Favourites"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			if tt.custom != "" {
+				if err := service.db.Create(&models.Shelf{UserID: 1, Name: tt.custom}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Twice, as concurrent requests may both create them
+			for range 2 {
+				if err := service.ensureDefaultShelves(service.db, 1); err != nil {
+					t.Fatalf("ensureDefaultShelves: %v", err)
+				}
+			}
+
+			var shelves []models.Shelf
+			if err := service.db.Where("user_id = ? AND is_default", 1).Find(&shelves).Error; err != nil {
+				t.Fatal(err)
+			}
+			statuses := make(map[string]int)
+			for _, shelf := range shelves {
+				if shelf.Name == tt.custom {
+					t.Errorf("custom shelf %q was adopted as a default shelf", tt.custom)
+				}
+				statuses[shelf.Status]++
+			}
+			for _, def := range models.DefaultShelves {
+				// A custom shelf keeps its name, leaving no room for the default
+				want := 1
+				if def.Name == tt.custom {
+					want = 0
+				}
+				if statuses[def.Status] != want {
+					t.Errorf("%d default shelves for status %s, want %d", statuses[def.Status], def.Status, want)
+				}
+			}
+		})
+	}
+}
+
+func TestShelfErrors(t *testing.T) {
+	service, bookID := newTestService(t)
+	custom, err := service.CreateShelf(1, &dtos.CreateShelfRequest{Name: "Favourites"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := service.CreateShelf(1, &dtos.CreateShelfRequest{Name: "Classics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AddEntry(custom.ID, 1, &dtos.AddShelfEntryRequest{BookID: bookID}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{
+			name: "create with default name",
+			call: func() error {
+				_, err := service.CreateShelf(1, &dtos.CreateShelfRequest{Name: "Read"})
+				return err
+			},
+			want: ErrReservedName,
+		},
+		{
+			name: "create with default name in another case",
+			call: func() error {
+				_, err := service.CreateShelf(1, &dtos.CreateShelfRequest{Name: "currently reading"})
+				return err
+			},
+			want: ErrReservedName,
+		},
+		{
+			name: "create with taken name",
+			call: func() error {
+				_, err := service.CreateShelf(1, &dtos.CreateShelfRequest{Name: "Favourites"})
+				return err
+			},
+			want: ErrShelfExists,
+		},
+		{
+			name: "rename to default name",
+			call: func() error {
+				_, err := service.UpdateShelf(other.ID, 1, &dtos.UpdateShelfRequest{Name: "Want to Read"})
+				return err
+			},
+			want: ErrReservedName,
+		},
+		{
+			name: "rename to taken name",
+			call: func() error {
+				_, err := service.UpdateShelf(other.ID, 1, &dtos.UpdateShelfRequest{Name: "Favourites"})
+				return err
+			},
+			want: ErrShelfExists,
+		},
+		{
+			name: "add book already on another shelf",
+			call: func() error {
+				_, err := service.AddEntry(other.ID, 1, &dtos.AddShelfEntryRequest{BookID: bookID})
+				return err
+			},
+			want: ErrAlreadyOnShelf,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
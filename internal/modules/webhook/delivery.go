@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// claimBatch is the number of deliveries claimed at once
+	claimBatch = 50
+	// deliveryConcurrency bounds the requests in flight per instance
+	deliveryConcurrency = 8
+	// maxBackoff caps the wait between retries
+	maxBackoff = 6 * time.Hour
+	// maxResponseBody is the length of response bodies kept in the log,
+	// for operators, the API never returns them
+	maxResponseBody = 1024
+)
+
+// RunDeliveryJob periodically delivers the deliveries that are due until ctx is cancelled.
+func (s *Service) RunDeliveryJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Drain a backlog without waiting for the next tick
+			for {
+				attempted, err := s.DeliverDue(ctx)
+				if err != nil {
+					slog.Error("Webhook delivery failed", "error", err)
+				}
+				if attempted < claimBatch || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DeliverDue attempts a batch of the pending deliveries whose next attempt
+// is due and returns how many it attempted. Claiming a delivery moves its
+// next attempt past the attempt timeout, so other instances skip it, and a
+// crash mid-attempt only delays it.
+func (s *Service) DeliverDue(ctx context.Context) (int, error) {
+	db := s.db.WithContext(ctx)
+
+	var due []models.WebhookDelivery
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(claimBatch).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(due))
+		for i := range due {
+			ids[i] = due[i].ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(s.cfg.Timeout+time.Minute)).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claiming deliveries: %w", err)
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	webhookIDs := make([]uint, len(due))
+	for i := range due {
+		webhookIDs[i] = due[i].WebhookID
+	}
+	var webhooks []models.Webhook
+	if err := db.Where("id IN ?", webhookIDs).Find(&webhooks).Error; err != nil {
+		return 0, fmt.Errorf("fetching webhooks: %w", err)
+	}
+	byID := make(map[uint]*models.Webhook, len(webhooks))
+	for i := range webhooks {
+		byID[webhooks[i].ID] = &webhooks[i]
+	}
+
+	// Attempts in flight finish on shutdown, within the attempt timeout
+	attemptCtx := context.WithoutCancel(ctx)
+	sem := make(chan struct{}, deliveryConcurrency)
+	var wg sync.WaitGroup
+	for i := range due {
+		delivery := &due[i]
+		webhook := byID[delivery.WebhookID]
+		if webhook == nil || !webhook.Active {
+			s.finish(attemptCtx, delivery, nil, map[string]interface{}{
+				"status":          models.WebhookDeliveryDead,
+				"next_attempt_at": nil,
+				"last_error":      "webhook is inactive",
+			})
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.attempt(attemptCtx, webhook, delivery, s.cfg.MaxAttempts)
+		}()
+	}
+	wg.Wait()
+	return len(due), nil
+}
+
+// attempt sends a delivery once and records the outcome. A failed delivery
+// is retried with exponential backoff until it has been attempted
+// maxAttempts times, then it is dead-lettered.
+func (s *Service) attempt(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery, maxAttempts int) {
+	start := time.Now()
+	statusCode, body, err := s.send(ctx, webhook, delivery)
+
+	record := models.WebhookAttempt{
+		DeliveryID:   delivery.ID,
+		StatusCode:   statusCode,
+		ResponseBody: body,
+		DurationMS:   time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	attempts := delivery.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":         attempts,
+		"last_status_code": statusCode,
+		"last_error":       record.Error,
+	}
+	switch {
+	case err == nil:
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["next_attempt_at"] = nil
+		updates["delivered_at"] = time.Now()
+		metrics.WebhookDeliveryAttempts.WithLabelValues("succeeded").Inc()
+	case attempts >= maxAttempts:
+		updates["status"] = models.WebhookDeliveryDead
+		updates["next_attempt_at"] = nil
+		metrics.WebhookDeliveryAttempts.WithLabelValues("dead").Inc()
+		slog.WarnContext(ctx, "Webhook delivery dead-lettered",
+			"webhook_id", webhook.ID, "delivery_id", delivery.ID, "event", delivery.Event, "attempts", attempts, "error", err)
+	default:
+		updates["next_attempt_at"] = time.Now().Add(s.backoff(attempts))
+		metrics.WebhookDeliveryAttempts.WithLabelValues("failed").Inc()
+	}
+
+	s.finish(ctx, delivery, &record, updates)
+}
+
+// finish applies updates to a delivery, logging the attempt if there was one.
+func (s *Service) finish(ctx context.Context, delivery *models.WebhookDelivery, record *models.WebhookAttempt, updates map[string]interface{}) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if record != nil {
+			if err := tx.Create(record).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(delivery).Updates(updates).Error
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// send POSTs the payload of a delivery to its webhook and returns the
+// response status and the start of its body. Any status but 2xx is an
+// error.
+func (s *Service) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-fiber-api-webhooks/1.0")
+	req.Header.Set("X-Webhook-ID", delivery.EventID)
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// backoff returns the wait before the retry following the given number of
+// attempts.
+func (s *Service) backoff(attempts int) time.Duration {
+	wait := s.cfg.RetryBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// Sign returns the signature of a delivery: the hex-encoded HMAC-SHA256,
+// keyed with the webhook secret, of the X-Webhook-Timestamp header, a dot
+// and the body. Receivers compute it the same way to authenticate
+// deliveries, and reject old timestamps to prevent replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"evt_1","type":"book.created"}`)
+	want := func(secret, timestamp string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+	}{
+		{"payload", "whsec_1", "1700000000", body},
+		{"empty body", "whsec_1", "1700000000", nil},
+		{"other secret", "whsec_2", "1700000000", body},
+		{"other timestamp", "whsec_1", "1700000001", body},
+	}
+
+	seen := make(map[string]string)
+	for _, tt := range tests {
+		got := Sign(tt.secret, tt.timestamp, tt.body)
+		if got != want(tt.secret, tt.timestamp, tt.body) {
+			t.Errorf("%s: signature %s, want %s", tt.name, got, want(tt.secret, tt.timestamp, tt.body))
+		}
+		if other, ok := seen[got]; ok {
+			t.Errorf("%s: same signature as %s", tt.name, other)
+		}
+		seen[got] = tt.name
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	service := &Service{cfg: Config{RetryBackoff: 30 * time.Second}}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{9, 128 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, maxBackoff},
+		{100, maxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := service.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// Docs describes the routes registered by SetupRoutes for the OpenAPI document.
+func Docs() openapi.Module {
+	return openapi.Module{
+		Tag:         "Webhooks",
+		Description: "Signed HTTP callbacks for book and user events",
+		Routes: []openapi.Route{
+			{Method: http.MethodGet, Path: "/webhooks/", Summary: "List your webhooks", Auth: openapi.Authenticated, Response: []dtos.WebhookResponse{}},
+			{
+				Method: http.MethodPost, Path: "/webhooks/", Summary: "Subscribe a URL to events", Auth: openapi.Authenticated,
+				Description: "The response includes the secret that signs deliveries, it is not shown again. Only admins may create global webhooks.",
+				Request:     dtos.CreateWebhookRequest{}, Status: http.StatusCreated, Response: dtos.WebhookResponse{},
+			},
+			{Method: http.MethodGet, Path: "/webhooks/:id", Summary: "Get a webhook", Auth: openapi.Authenticated, Response: dtos.WebhookResponse{}},
+			{Method: http.MethodPut, Path: "/webhooks/:id", Summary: "Update a webhook", Auth: openapi.Authenticated, Request: dtos.UpdateWebhookRequest{}, Response: dtos.WebhookResponse{}},
+			{Method: http.MethodDelete, Path: "/webhooks/:id", Summary: "Delete a webhook and its deliveries", Auth: openapi.Authenticated},
+			{Method: http.MethodPost, Path: "/webhooks/:id/ping", Summary: "Send a test event and wait for the response", Auth: openapi.Authenticated, Response: dtos.WebhookDeliveryResponse{}},
+			{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Summary: "List deliveries, newest first", Auth: openapi.Authenticated, Query: dtos.WebhookDeliveryFilter{}, Response: []dtos.WebhookDeliveryResponse{}, Meta: dtos.PageMeta{}},
+			{Method: http.MethodGet, Path: "/webhooks/:id/deliveries/:deliveryId", Summary: "Get a delivery with its payload and attempts", Auth: openapi.Authenticated, Response: dtos.WebhookDeliveryResponse{}},
+			{Method: http.MethodPost, Path: "/webhooks/:id/deliveries/:deliveryId/redeliver", Summary: "Queue a delivery again", Auth: openapi.Authenticated, Status: http.StatusAccepted, Response: dtos.WebhookDeliveryResponse{}},
+		},
+	}
+}
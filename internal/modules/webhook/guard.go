@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errBlockedAddress is the error of connections refused by guardDial.
+var errBlockedAddress = errors.New("webhook destination is not allowed")
+
+// blockedPrefixes are the ranges refused besides those the netip.Addr
+// predicates cover: "this network", carrier-grade NAT, the IPv4/IPv6
+// translation prefixes and benchmarking.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// isBlocked reports whether deliveries may not reach ip: loopback, private,
+// link-local (which holds cloud metadata endpoints such as 169.254.169.254),
+// unspecified, multicast and the blockedPrefixes.
+func isBlocked(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() ||
+		ip.IsMulticast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlockedHost reports whether a URL host is an address or name that
+// always resolves to a blocked address. Other names are checked when they
+// are dialed.
+func isBlockedHost(host string) bool {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return isBlocked(ip)
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host == "localhost" || strings.HasSuffix(host, ".localhost")
+}
+
+// guardDial is a net.Dialer Control function refusing blocked addresses. It
+// runs on every connection, after the name was resolved, so a host pointed
+// at an internal address after it was registered is refused too.
+func guardDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if isBlocked(ip) {
+		return fmt.Errorf("%w: %s", errBlockedAddress, ip)
+	}
+	return nil
+}
+
+// newTransport returns the transport of deliveries, guarded by guardDial
+// unless allowPrivate is set. It ignores proxy settings, so a proxy cannot
+// reach the addresses the guard refuses.
+func newTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = guardDial
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+)
+
+func TestIsBlocked(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00:ec2::254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"224.0.0.1", true},
+		{"ff02::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"64:ff9b::a00:1", true},
+		{"93.184.216.34", false},
+		{"100.128.0.1", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+	}
+
+	for _, tt := range tests {
+		if got := isBlocked(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("isBlocked(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		url     string
+		wantErr bool
+	}{
+		{name: "https", url: "https://example.com/hook"},
+		{name: "http", url: "http://example.com/hook", wantErr: true},
+		{name: "relative", url: "/hook", wantErr: true},
+		{name: "loopback", url: "https://127.0.0.1/hook", wantErr: true},
+		{name: "localhost", url: "https://localhost:8080/hook", wantErr: true},
+		{name: "metadata", url: "https://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "private IPv6", url: "https://[fd00::1]/hook", wantErr: true},
+		{name: "public IP", url: "https://93.184.216.34/hook"},
+		{name: "development", cfg: Config{AllowHTTP: true, AllowPrivate: true}, url: "http://localhost:8080/hook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{cfg: tt.cfg}
+			if err := service.validateURL(tt.url); (err != nil) != tt.wantErr {
+				t.Errorf("error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSendGuard checks that the guard runs when a delivery is sent, not
+// only when its URL is validated.
+func TestSendGuard(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name         string
+		allowPrivate bool
+		wantErr      error
+		wantStatus   int
+	}{
+		{name: "guarded", allowPrivate: false, wantErr: errBlockedAddress},
+		{name: "development", allowPrivate: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, Config{Timeout: time.Second, AllowHTTP: true, AllowPrivate: tt.allowPrivate})
+			webhook := &models.Webhook{URL: receiver.URL, Secret: "secret"}
+			delivery := &models.WebhookDelivery{EventID: "evt_1", Event: PingEvent, Payload: "{}"}
+
+			status, _, err := service.send(t.Context(), webhook, delivery)
+			if !errors.Is(err, tt.wantErr) || status != tt.wantStatus {
+				t.Errorf("send = %d, %v, want %d, %v", status, err, tt.wantStatus, tt.wantErr)
+			}
+		})
+	}
+}
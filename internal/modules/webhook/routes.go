@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+)
+
+func SetupRoutes(router fiber.Router, controller *Controller, authMiddleware *middleware.AuthMiddleware) {
+	webhooks := router.Group("/webhooks", authMiddleware.RequireAuth)
+
+	webhooks.Get("/", controller.ListWebhooks)
+	webhooks.Post("/", controller.CreateWebhook)
+	webhooks.Get("/:id", controller.GetWebhook)
+	webhooks.Put("/:id", controller.UpdateWebhook)
+	webhooks.Delete("/:id", controller.DeleteWebhook)
+	webhooks.Post("/:id/ping", controller.PingWebhook)
+	webhooks.Get("/:id/deliveries", controller.ListDeliveries)
+	webhooks.Get("/:id/deliveries/:deliveryId", controller.GetDelivery)
+	webhooks.Post("/:id/deliveries/:deliveryId/redeliver", controller.Redeliver)
+}
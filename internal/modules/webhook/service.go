@@ -0,0 +1,421 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/dtos"
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Config controls how deliveries are made.
+type Config struct {
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered
+	MaxAttempts int
+	// RetryBackoff is the wait before the first retry, doubled after each
+	// failed attempt up to maxBackoff
+	RetryBackoff time.Duration
+	// AllowHTTP accepts plain http:// URLs, for development
+	AllowHTTP bool
+	// AllowPrivate lets deliveries reach loopback, private and link-local
+	// addresses, for development
+	AllowPrivate bool
+}
+
+// PingEvent is the type of test deliveries sent by Ping. Webhooks cannot
+// subscribe to it, every webhook can be pinged.
+const PingEvent = "webhook.ping"
+
+// Service manages webhooks and delivers events to them. It is an outbox
+// sink, queueing deliveries of the events the relay hands it.
+type Service struct {
+	db     *gorm.DB
+	ctx    context.Context
+	cfg    Config
+	client *http.Client
+}
+
+func NewService(db *gorm.DB, cfg Config) *Service {
+	return &Service{
+		db:  db,
+		ctx: context.Background(),
+		cfg: cfg,
+		client: &http.Client{
+			Transport: newTransport(cfg.AllowPrivate),
+			Timeout:   cfg.Timeout,
+			// A redirect is a failed delivery, the receiver must fix its URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// WithContext returns a copy of the service whose queries and test pings
+// run with ctx.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	clone := *s
+	clone.db = s.db.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+func (s *Service) ListWebhooks(userID uint) ([]dtos.WebhookResponse, error) {
+	var webhooks []models.Webhook
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, errors.New("failed to fetch webhooks")
+	}
+
+	responses := make([]dtos.WebhookResponse, len(webhooks))
+	for i := range webhooks {
+		responses[i] = *toWebhookResponse(&webhooks[i])
+	}
+	return responses, nil
+}
+
+func (s *Service) GetWebhook(id uint, userID uint) (*dtos.WebhookResponse, error) {
+	webhook, err := s.findWebhook(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookResponse(webhook), nil
+}
+
+// CreateWebhook subscribes a URL to events. The response is the only one
+// that includes the signing secret.
+func (s *Service) CreateWebhook(userID uint, role string, req *dtos.CreateWebhookRequest) (*dtos.WebhookResponse, error) {
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := joinEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	if req.Global && role != models.RoleAdmin {
+		return nil, errors.New("only admins can create global webhooks")
+	}
+
+	webhook := models.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: newSecret(),
+		Events: eventTypes,
+		Global: req.Global,
+		Active: true,
+	}
+	if err := s.db.Create(&webhook).Error; err != nil {
+		return nil, errors.New("failed to create webhook")
+	}
+
+	response := toWebhookResponse(&webhook)
+	response.Secret = webhook.Secret
+	return response, nil
+}
+
+func (s *Service) UpdateWebhook(id uint, userID uint, req *dtos.UpdateWebhookRequest) (*dtos.WebhookResponse, error) {
+	webhook, err := s.findWebhook(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		if err := s.validateURL(req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = req.URL
+	}
+	if req.Events != nil {
+		if webhook.Events, err = joinEvents(req.Events); err != nil {
+			return nil, err
+		}
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.db.Save(webhook).Error; err != nil {
+		return nil, errors.New("failed to update webhook")
+	}
+	return toWebhookResponse(webhook), nil
+}
+
+// DeleteWebhook removes a webhook along with its deliveries.
+func (s *Service) DeleteWebhook(id uint, userID uint) error {
+	webhook, err := s.findWebhook(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(webhook).Error; err != nil {
+		return errors.New("failed to delete webhook")
+	}
+	return nil
+}
+
+// Ping sends a test event to a webhook right away, whether or not it is
+// active, and returns the delivery with its log. Pings are not retried.
+func (s *Service) Ping(id uint, userID uint) (*dtos.WebhookDeliveryResponse, error) {
+	webhook, err := s.findWebhook(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := events.New(PingEvent, webhook.ID, userID, map[string]uint{"webhook_id": webhook.ID})
+	delivery, err := newDelivery(webhook, event)
+	if err != nil {
+		return nil, err
+	}
+	delivery.NextAttemptAt = nil
+	if err := s.db.Create(delivery).Error; err != nil {
+		return nil, errors.New("failed to create delivery")
+	}
+
+	s.attempt(s.ctx, webhook, delivery, 1)
+	return s.GetDelivery(webhook.ID, delivery.ID, userID)
+}
+
+func (s *Service) ListDeliveries(webhookID uint, userID uint, filter *dtos.WebhookDeliveryFilter) ([]dtos.WebhookDeliveryResponse, int64, error) {
+	if _, err := s.findWebhook(webhookID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	switch filter.Status {
+	case "":
+	case models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryDead:
+		query = query.Where("status = ?", filter.Status)
+	default:
+		return nil, 0, errors.New("invalid status, must be pending, succeeded or dead")
+	}
+	if filter.Event != "" {
+		query = query.Where("event = ?", filter.Event)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count deliveries")
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Find(&deliveries).Error; err != nil {
+		return nil, 0, errors.New("failed to fetch deliveries")
+	}
+
+	responses := make([]dtos.WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = *toDeliveryResponse(&deliveries[i])
+	}
+	return responses, total, nil
+}
+
+// GetDelivery returns a delivery with its payload and the log of its
+// attempts.
+func (s *Service) GetDelivery(webhookID uint, id uint, userID uint) (*dtos.WebhookDeliveryResponse, error) {
+	delivery, err := s.findDelivery(webhookID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("delivery_id = ?", delivery.ID).Order("id").Find(&delivery.Log).Error; err != nil {
+		return nil, errors.New("failed to fetch delivery log")
+	}
+
+	response := toDeliveryResponse(delivery)
+	response.Payload = json.RawMessage(delivery.Payload)
+	for _, attempt := range delivery.Log {
+		response.Log = append(response.Log, dtos.WebhookAttemptResponse{
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMS: attempt.DurationMS,
+			CreatedAt:  attempt.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// Redeliver queues a succeeded or dead-lettered delivery again, with the
+// same payload and a fresh set of attempts.
+func (s *Service) Redeliver(webhookID uint, id uint, userID uint) (*dtos.WebhookDeliveryResponse, error) {
+	delivery, err := s.findDelivery(webhookID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		return nil, errors.New("delivery is already pending")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(delivery).Updates(map[string]interface{}{
+		"status":          models.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"delivered_at":    nil,
+	}).Error; err != nil {
+		return nil, errors.New("failed to queue delivery")
+	}
+	return s.GetDelivery(webhookID, id, userID)
+}
+
+// Publish queues a delivery of event to each active webhook subscribed to
+// it: those of the user it concerns and the global ones. Publishing an
+// event again queues no duplicates.
+func (s *Service) Publish(ctx context.Context, event events.Event) error {
+	db := s.db.WithContext(ctx)
+
+	var webhooks []models.Webhook
+	if err := db.Where("active = ? AND (user_id = ? OR global = ?)", true, event.UserID, true).Find(&webhooks).Error; err != nil {
+		return fmt.Errorf("finding webhooks: %w", err)
+	}
+
+	var deliveries []*models.WebhookDelivery
+	for i := range webhooks {
+		if !subscribed(&webhooks[i], event.Type) {
+			continue
+		}
+		delivery, err := newDelivery(&webhooks[i], event)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(deliveries).Error; err != nil {
+		return fmt.Errorf("queueing deliveries: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) findWebhook(id uint, userID uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.db.Where("user_id = ?", userID).First(&webhook, id).Error; err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	return &webhook, nil
+}
+
+func (s *Service) findDelivery(webhookID uint, id uint, userID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.findWebhook(webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	var delivery models.WebhookDelivery
+	if err := s.db.Where("webhook_id = ?", webhookID).First(&delivery, id).Error; err != nil {
+		return nil, errors.New("delivery not found")
+	}
+	return &delivery, nil
+}
+
+func (s *Service) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && (!s.cfg.AllowHTTP || u.Scheme != "http")) {
+		if s.cfg.AllowHTTP {
+			return errors.New("url must be an absolute http or https URL")
+		}
+		return errors.New("url must be an absolute https URL")
+	}
+	// Names are checked again on every delivery, once resolved
+	if !s.cfg.AllowPrivate && isBlockedHost(u.Hostname()) {
+		return errors.New("url must not point to a local or private address")
+	}
+	return nil
+}
+
+// newDelivery encodes event for webhook, due now.
+func newDelivery(webhook *models.Webhook, event events.Event) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(dtos.WebhookPayload{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.OccurredAt,
+		Data:      event.Data,
+	})
+	if err != nil {
+		return nil, errors.New("failed to encode payload")
+	}
+
+	now := time.Now()
+	return &models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       event.ID,
+		Event:         event.Type,
+		Payload:       string(payload),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	// crypto/rand.Read does not fail on supported platforms
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// joinEvents validates event types and encodes them for Webhook.Events.
+func joinEvents(eventTypes []string) (string, error) {
+	for _, eventType := range eventTypes {
+		if !events.IsValidType(eventType) {
+			return "", errors.New("unknown event type " + strconv.Quote(eventType))
+		}
+	}
+	return strings.Join(eventTypes, ","), nil
+}
+
+func splitEvents(eventTypes string) []string {
+	if eventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(eventTypes, ",")
+}
+
+func subscribed(webhook *models.Webhook, eventType string) bool {
+	return webhook.Events == "" || slices.Contains(splitEvents(webhook.Events), eventType)
+}
+
+func toWebhookResponse(webhook *models.Webhook) *dtos.WebhookResponse {
+	return &dtos.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    splitEvents(webhook.Events),
+		Global:    webhook.Global,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+func toDeliveryResponse(delivery *models.WebhookDelivery) *dtos.WebhookDeliveryResponse {
+	return &dtos.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
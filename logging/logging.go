@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute, header and JSON field names whose values never reach the logs.
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"password":      true,
+	"token":         true,
+	"jwt_secret":    true,
+	"cookie":        true,
+	"set-cookie":    true,
+}
+
+// IsSensitive reports whether values stored under key must be redacted.
+func IsSensitive(key string) bool {
+	return sensitiveKeys[strings.ToLower(key)]
+}
+
+// Setup builds the application logger for the given level (debug, info, warn,
+// error) and format (json, text) and installs it as the slog default.
+func Setup(level, format string) (*slog.Logger, error) {
+	return setup(os.Stdout, level, format)
+}
+
+// SetupTo is Setup writing to w, for commands that keep stdout for their output.
+func SetupTo(w io.Writer, level, format string) (*slog.Logger, error) {
+	return setup(w, level, format)
+}
+
+func setup(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       lvl,
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+func redactAttr(_ []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	return attr
+}
+
+// RedactJSON replaces sensitive fields in a decoded JSON value, recursing into
+// nested objects and arrays.
+func RedactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if IsSensitive(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = RedactJSON(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = RedactJSON(v[i])
+		}
+	}
+	return value
+}
+
+// Fatal logs msg with err at error level and exits.
+func Fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -1,77 +1,62 @@
 package main
 
 import (
-	"log"
+	"flag"
+	"fmt"
 	"os"
 
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/rakibulbanna/go-fiber-postgres/config"
-	authModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
-	bookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
-	"github.com/rakibulbanna/go-fiber-postgres/middleware"
-	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/logging"
 )
 
+const usage = `Usage: app [command] [flags]
+
+Commands:
+  serve          Run the HTTP server (default)
+  config print   Print the effective configuration
+  migrate        Apply, revert and inspect database migrations
+  seed           Fill the database with deterministic test data
+  admin          Manage users: create, reset passwords, roles, disable, revoke sessions
+
+Run "app <command> -h" for the flags of a command.
+`
+
 func main() {
-	// Load configuration
-	envFile := os.Getenv("ENV_FILE")
-	if envFile == "" {
-		envFile = ".env.dev"
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
 	}
-	cfg := config.LoadConfig(envFile)
 
-	// Database connection
-	dbConfig := storage.Config{
-		Host:     os.Getenv("DB_HOST"),
-		Port:     os.Getenv("DB_PORT"),
-		User:     os.Getenv("DB_USER"),
-		Password: os.Getenv("DB_PASSWORD"),
-		DBName:   os.Getenv("DB_NAME"),
-		SSLMode:  os.Getenv("DB_SSLMODE"),
+	switch command {
+	case "serve":
+		serve(args)
+	case "config":
+		configCommand(args)
+	case "migrate":
+		migrateCommand(args)
+	case "seed":
+		seedCommand(args)
+	case "admin":
+		adminCommand(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
 	}
+}
 
-	db, err := storage.NewConnection(dbConfig)
+// loadConfig parses the configuration flags of a command, along with any
+// flags the command registered on fs, and validates the result. It exits on
+// error so that no command runs with a bad configuration.
+func loadConfig(fs *flag.FlagSet, args []string) *config.Config {
+	cfg, err := config.Load(fs, args)
 	if err != nil {
-		log.Fatal("Error connecting to database: ", err)
+		logging.Fatal("Error loading configuration", err)
 	}
-
-	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTSecret)
-
-	// Initialize modules
-	authService := authModule.NewService(db, cfg.JWTSecret)
-	authController := authModule.NewController(authService)
-
-	bookService := bookModule.NewService(db)
-	bookController := bookModule.NewController(bookService)
-
-	// Initialize Fiber app
-	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-			return c.Status(code).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		},
-	})
-
-	// Middleware
-	app.Use(recover.New())
-	app.Use(logger.New())
-
-	// Setup routes
-	api := app.Group("/api")
-	authModule.SetupRoutes(api, authController)
-	bookModule.SetupRoutes(api, bookController, authMiddleware)
-
-	// Start server
-	log.Printf("Server starting on port %s", cfg.Port)
-	if err := app.Listen(":" + cfg.Port); err != nil {
-		log.Fatal("Error starting server: ", err)
+	if err := cfg.Validate(); err != nil {
+		logging.Fatal("Invalid configuration", err)
 	}
+	return cfg
 }
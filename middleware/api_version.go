@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
+)
+
+// Deprecation describes a deprecated API version for the headers of
+// RFC 9745 (Deprecation) and RFC 8594 (Sunset).
+type Deprecation struct {
+	At time.Time
+	// Sunset is when the version will be removed, zero when undecided
+	Sunset time.Time
+	// SuccessorPrefix replaces the version's prefix in the successor-version
+	// link, e.g. "/api/v2"
+	SuccessorPrefix string
+	// URL of a migration guide, optional
+	URL string
+}
+
+// APIVersion tags requests under prefix with the API version and the prefix
+// (Locals "apiVersion" and "apiPrefix", for building links), counts them
+// per version and route, and announces deprecation when deprecation is set.
+// Groups nest by path, so the unversioned /api group also sees /api/v2
+// requests: the first APIVersion handler a request passes through wins,
+// which requires registering the versioned groups first.
+func APIVersion(version, prefix string, deprecation *Deprecation) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if _, tagged := ctx.Locals("apiVersion").(string); tagged {
+			return ctx.Next()
+		}
+		ctx.Locals("apiVersion", version)
+		ctx.Locals("apiPrefix", prefix)
+
+		if deprecation != nil {
+			ctx.Set("Deprecation", "@"+strconv.FormatInt(deprecation.At.Unix(), 10))
+			if !deprecation.Sunset.IsZero() {
+				ctx.Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+			}
+			links := []string{`<` + deprecation.SuccessorPrefix + strings.TrimPrefix(ctx.Path(), prefix) + `>; rel="successor-version"`}
+			if deprecation.URL != "" {
+				links = append(links, `<`+deprecation.URL+`>; rel="deprecation"; type="text/html"`)
+			}
+			ctx.Set(fiber.HeaderLink, strings.Join(links, ", "))
+		}
+
+		err := ctx.Next()
+		metrics.APIRequests.WithLabelValues(version, ctx.Method(), ctx.Route().Path).Inc()
+		return err
+	}
+}
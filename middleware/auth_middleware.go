@@ -1,16 +1,27 @@
 package middleware
 
 import (
+	"context"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
 	"github.com/rakibulbanna/go-fiber-postgres/utils"
 )
 
+// SessionValidator checks that a token's user, and impersonator if any, may
+// still use it and returns the user's current role.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, claims *utils.Claims) (string, error)
+}
+
 type AuthMiddleware struct {
 	jwtSecret string
+	sessions  SessionValidator
 }
 
-func NewAuthMiddleware(jwtSecret string) *AuthMiddleware {
-	return &AuthMiddleware{jwtSecret: jwtSecret}
+func NewAuthMiddleware(jwtSecret string, sessions SessionValidator) *AuthMiddleware {
+	return &AuthMiddleware{jwtSecret: jwtSecret, sessions: sessions}
 }
 
 func (m *AuthMiddleware) RequireAuth(ctx *fiber.Ctx) error {
@@ -39,9 +50,56 @@ func (m *AuthMiddleware) RequireAuth(ctx *fiber.Ctx) error {
 		})
 	}
 
+	// Disabled accounts and revoked sessions
+	role, err := m.sessions.ValidateSession(ctx.UserContext(), claims)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid or expired token",
+		})
+	}
+
+	setUser(ctx, claims, role)
+
+	return ctx.Next()
+}
+
+// OptionalAuth identifies the user when a valid bearer token is present but
+// lets anonymous requests through, for public routes that behave differently
+// for signed-in users.
+func (m *AuthMiddleware) OptionalAuth(ctx *fiber.Ctx) error {
+	authHeader := ctx.Get("Authorization")
+	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+		if claims, err := utils.ValidateToken(authHeader[7:], m.jwtSecret); err == nil {
+			if role, err := m.sessions.ValidateSession(ctx.UserContext(), claims); err == nil {
+				setUser(ctx, claims, role)
+			}
+		}
+	}
+	return ctx.Next()
+}
+
+func setUser(ctx *fiber.Ctx, claims *utils.Claims, role string) {
 	// Store user info in context
 	ctx.Locals("userID", claims.UserID)
 	ctx.Locals("userEmail", claims.Email)
+	ctx.Locals("userRole", role)
+	if claims.ImpersonatorID != 0 {
+		ctx.Locals("impersonatorID", claims.ImpersonatorID)
+	}
 
-	return ctx.Next()
+	// Reads right after this user's writes go to the primary
+	ctx.SetUserContext(storage.WithSession(ctx.UserContext(), "user:"+strconv.FormatUint(uint64(claims.UserID), 10)))
+}
+
+// RequireRole rejects users without the given role. It must run after
+// RequireAuth.
+func (m *AuthMiddleware) RequireRole(role string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if userRole, _ := ctx.Locals("userRole").(string); userRole != role {
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient permissions",
+			})
+		}
+		return ctx.Next()
+	}
 }
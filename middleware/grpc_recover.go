@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCRecoverUnary turns panics in unary gRPC methods into Internal errors,
+// as the recover middleware does for HTTP handlers.
+func GRPCRecoverUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer recoverGRPC(ctx, info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+// GRPCRecoverStream turns panics in streaming gRPC methods into Internal
+// errors.
+func GRPCRecoverStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recoverGRPC(stream.Context(), info.FullMethod, &err)
+	return handler(srv, stream)
+}
+
+func recoverGRPC(ctx context.Context, method string, err *error) {
+	if r := recover(); r != nil {
+		slog.ErrorContext(ctx, "Panic in gRPC method", "method", method, "panic", r, "stack", string(debug.Stack()))
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
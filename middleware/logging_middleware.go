@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/logging"
+	"github.com/rakibulbanna/go-fiber-postgres/tracing"
+)
+
+// RequestLogger writes one structured access log line per request. It must
+// run after the requestid middleware so the X-Request-ID is available.
+func RequestLogger(ctx *fiber.Ctx) error {
+	start := time.Now()
+	err := ctx.Next()
+	latency := time.Since(start)
+
+	status := ctx.Response().StatusCode()
+	if err != nil {
+		status = fiber.StatusInternalServerError
+		if e, ok := err.(*fiber.Error); ok {
+			status = e.Code
+		}
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", requestID(ctx)),
+		slog.String("method", ctx.Method()),
+		slog.String("route", ctx.Route().Path),
+		slog.String("path", ctx.Path()),
+		slog.Int("status", status),
+		slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+		slog.String("ip", ctx.IP()),
+	}
+	if userID, ok := ctx.Locals("userID").(uint); ok {
+		attrs = append(attrs, slog.Uint64("user_id", uint64(userID)))
+	}
+	if impersonatorID, ok := ctx.Locals("impersonatorID").(uint); ok {
+		attrs = append(attrs, slog.Uint64("impersonator_id", uint64(impersonatorID)))
+	}
+	if traceID := tracing.TraceID(ctx.UserContext()); traceID != "" {
+		attrs = append(attrs, slog.String("trace_id", traceID))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	// Headers and bodies are only logged at debug level, with secrets redacted
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		attrs = append(attrs, slog.Any("headers", redactedHeaders(ctx)))
+		if body := redactedBody(ctx); body != nil {
+			attrs = append(attrs, slog.Any("body", body))
+		}
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= fiber.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+
+	slog.LogAttrs(ctx.UserContext(), level, "request", attrs...)
+	return err
+}
+
+func requestID(ctx *fiber.Ctx) string {
+	if id, ok := ctx.Locals("requestid").(string); ok {
+		return id
+	}
+	return ctx.GetRespHeader(fiber.HeaderXRequestID)
+}
+
+func redactedHeaders(ctx *fiber.Ctx) map[string]string {
+	headers := make(map[string]string)
+	ctx.Request().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if logging.IsSensitive(name) {
+			headers[name] = "[REDACTED]"
+			return
+		}
+		headers[name] = string(value)
+	})
+	return headers
+}
+
+func redactedBody(ctx *fiber.Ctx) interface{} {
+	if len(ctx.Body()) == 0 || !ctx.Is("json") {
+		return nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(ctx.Body(), &body); err != nil {
+		return nil
+	}
+	return logging.RedactJSON(body)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
+)
+
+// Metrics records request counts and latency labeled by route template, so
+// /api/books/1 and /api/books/2 share the /api/books/:id series.
+func Metrics(ctx *fiber.Ctx) error {
+	start := time.Now()
+	err := ctx.Next()
+
+	status := ctx.Response().StatusCode()
+	if err != nil {
+		status = fiber.StatusInternalServerError
+		if e, ok := err.(*fiber.Error); ok {
+			status = e.Code
+		}
+	}
+
+	labels := []string{ctx.Method(), ctx.Route().Path, strconv.Itoa(status)}
+	metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+	return err
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier adapts fasthttp request headers to the OpenTelemetry propagator.
+type headerCarrier struct {
+	ctx *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string { return h.ctx.Get(key) }
+
+func (h headerCarrier) Set(key, value string) { h.ctx.Set(key, value) }
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.ctx.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Tracing starts a server span per request, continuing any W3C traceparent
+// sent by the caller. The span context is stored as the request's user
+// context so services can pass it on to GORM.
+func Tracing(ctx *fiber.Ctx) error {
+	parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), headerCarrier{ctx})
+	spanCtx, span := tracing.Tracer().Start(parent, ctx.Method()+" "+ctx.Path(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(ctx.Method()),
+			semconv.URLPath(ctx.Path()),
+		),
+	)
+	defer span.End()
+
+	ctx.SetUserContext(spanCtx)
+	traceID := tracing.TraceID(spanCtx)
+	if traceID != "" {
+		ctx.Set("X-Trace-Id", traceID)
+	}
+
+	err := ctx.Next()
+
+	status := ctx.Response().StatusCode()
+	if err != nil {
+		status = fiber.StatusInternalServerError
+		if e, ok := err.(*fiber.Error); ok {
+			status = e.Code
+		}
+		span.RecordError(err)
+	}
+
+	// Name the span after the route template once routing has happened
+	route := ctx.Route().Path
+	span.SetName(ctx.Method() + " " + route)
+	span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, "")
+	}
+
+	if status >= fiber.StatusBadRequest && traceID != "" {
+		addTraceIDToErrorBody(ctx, traceID)
+	}
+	return err
+}
+
+// addTraceIDToErrorBody adds a trace_id field to JSON error responses so
+// users can quote it when reporting a problem.
+func addTraceIDToErrorBody(ctx *fiber.Ctx, traceID string) {
+	resp := ctx.Response()
+	if resp.IsBodyStream() || !bytes.HasPrefix(resp.Header.ContentType(), []byte(fiber.MIMEApplicationJSON)) {
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return
+	}
+	body["trace_id"] = traceID
+
+	if encoded, err := json.Marshal(body); err == nil {
+		resp.SetBodyRaw(encoded)
+	}
+}
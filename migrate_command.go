@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/logging"
+	"github.com/rakibulbanna/go-fiber-postgres/migrations"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Usage: app migrate <command> [flags]
+
+Commands:
+  up        Apply pending migrations (--n to limit, --baseline for existing databases)
+  down      Revert applied migrations using migrations/down (--n, default 1)
+  status    List migrations and whether they are applied
+  validate  Check the migration files against atlas.sum
+  drift     Compare the database schema with the models, exits 1 on drift
+`
+
+// migrateCommand implements "app migrate up|down|status|validate".
+func migrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("migrate "+command, flag.ExitOnError)
+	n := fs.Int("n", 0, "Number of migrations to apply or revert")
+	baseline := fs.String("baseline", "", "Version an existing database is already at (up only)")
+	asJSON := fs.Bool("json", false, "Print status as JSON (status only)")
+
+	switch command {
+	case "up", "down", "status", "drift":
+	case "validate":
+		// Only the embedded files are checked, no database needed
+		runner, err := migrations.NewRunner(nil)
+		if err == nil {
+			err = runner.Validate()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("Migration directory is valid")
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s", command, migrateUsage)
+		os.Exit(2)
+	}
+
+	cfg := loadConfig(fs, args)
+	logger, err := logging.SetupTo(os.Stderr, cfg.LogLevel, "text")
+	if err != nil {
+		logging.Fatal("Error setting up logging", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := storage.NewConnection(ctx, storageConfig(cfg, logger))
+	if err != nil {
+		logging.Fatal("Error connecting to database", err)
+	}
+	defer storage.Close(db)
+
+	switch command {
+	case "up":
+		err = migrateUp(ctx, db, *n, *baseline)
+	case "down":
+		err = migrateDown(ctx, db, max(*n, 1))
+	case "status":
+		err = migrateStatus(ctx, db, *asJSON)
+	case "drift":
+		var diffs []migrations.Difference
+		if diffs, err = migrations.CheckDrift(ctx, db, models.All()...); err == nil {
+			err = migrations.WriteDrift(os.Stdout, diffs)
+		}
+		if err == nil && len(diffs) > 0 {
+			storage.Close(db)
+			os.Exit(1)
+		}
+	}
+	if err != nil {
+		storage.Close(db)
+		logging.Fatal("Migration failed", err)
+	}
+}
+
+func newRunner(db *gorm.DB) (*migrations.Runner, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrations.NewRunner(sqlDB)
+}
+
+// migrateUp validates and applies pending migrations. It is also used by
+// serve when MIGRATE_ON_START is set.
+func migrateUp(ctx context.Context, db *gorm.DB, n int, baseline string) error {
+	runner, err := newRunner(db)
+	if err != nil {
+		return err
+	}
+	if err := runner.Validate(); err != nil {
+		return err
+	}
+	applied, err := runner.Up(ctx, n, baseline)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		slog.Info("No pending migrations")
+		return nil
+	}
+	slog.Info("Migrations applied", "count", len(applied), "versions", applied)
+	return nil
+}
+
+func migrateDown(ctx context.Context, db *gorm.DB, n int) error {
+	runner, err := newRunner(db)
+	if err != nil {
+		return err
+	}
+	reverted, err := runner.Down(ctx, n)
+	if len(reverted) > 0 {
+		slog.Info("Migrations reverted", "count", len(reverted), "versions", reverted)
+	}
+	return err
+}
+
+func migrateStatus(ctx context.Context, db *gorm.DB, asJSON bool) error {
+	runner, err := newRunner(db)
+	if err != nil {
+		return err
+	}
+	status, err := runner.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tDESCRIPTION\tSTATE\tEXECUTED AT\tREVERSIBLE\tERROR")
+	for _, m := range status {
+		executedAt := "-"
+		if m.ExecutedAt != nil {
+			executedAt = m.ExecutedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\n", m.Version, m.Description, m.State, executedAt, m.Reversible, m.Error)
+	}
+	return w.Flush()
+}
+
+// warnSchemaDrift logs differences between the database and the models, so a
+// forgotten migration shows up at startup without stopping the server.
+func warnSchemaDrift(ctx context.Context, db *gorm.DB) {
+	diffs, err := migrations.CheckDrift(ctx, db, models.All()...)
+	if err != nil {
+		slog.Warn("Could not check schema drift", "error", err)
+		return
+	}
+	if len(diffs) == 0 {
+		return
+	}
+
+	details := make([]string, len(diffs))
+	for i, d := range diffs {
+		details[i] = d.String()
+	}
+	slog.Warn("Database schema differs from the models, run `migrate drift` for details", "count", len(diffs), "differences", details)
+}
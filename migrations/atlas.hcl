@@ -16,12 +16,12 @@ env "gorm" {
   url = var.db_url
   dev = "docker://postgres/15/dev?search_path=public"
   migration {
-    dir = "file://migrations"
+    dir = "file://migrations/sql"
   }
 }
 
 env "local" {
-  src = "file://migrations"
+  src = "file://migrations/sql"
   url = var.db_url
   dev = "docker://postgres/15/dev?search_path=public"
 }
@@ -0,0 +1,297 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Difference is one way the live schema deviates from the models.
+type Difference struct {
+	Table string
+	// Kind is "table", "column", "index" or "constraint"
+	Kind string
+	Name string
+	// Problem is "missing" (in the models, not the database), "unexpected"
+	// (in the database, not the models) or "changed"
+	Problem string
+	Detail  string
+}
+
+func (d Difference) String() string {
+	sign := map[string]string{"missing": "+", "unexpected": "-", "changed": "~"}[d.Problem]
+	name := d.Table
+	if d.Kind != "table" {
+		name += "." + d.Name
+	}
+	s := fmt.Sprintf("%s %s %s %s", sign, d.Kind, name, d.Problem)
+	if d.Detail != "" {
+		s += ": " + d.Detail
+	}
+	return s
+}
+
+// liveSchema is the part of the database schema the drift check compares.
+type liveSchema struct {
+	tables      map[string]bool
+	columns     map[string]map[string]liveColumn
+	indexes     map[string]map[string]bool
+	constraints map[string]map[string]bool
+}
+
+type liveColumn struct {
+	typeName string
+	nullable bool
+}
+
+// CheckDrift compares the tables, columns, indexes and constraints derived
+// from models with those in the current schema of db. It catches model
+// changes that were never turned into a migration, and manual changes to
+// the database.
+func CheckDrift(ctx context.Context, db *gorm.DB, models ...interface{}) ([]Difference, error) {
+	db = db.WithContext(ctx)
+	live, err := inspect(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []Difference
+	expectedTables := make(map[string]bool)
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		expectedTables[stmt.Schema.Table] = true
+		diffs = append(diffs, compareTable(db, stmt.Schema, live)...)
+	}
+
+	for table := range live.tables {
+		if !expectedTables[table] {
+			diffs = append(diffs, Difference{Table: table, Kind: "table", Problem: "unexpected"})
+		}
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Table < diffs[j].Table })
+	return diffs, nil
+}
+
+func compareTable(db *gorm.DB, sch *schema.Schema, live *liveSchema) []Difference {
+	table := sch.Table
+	if !live.tables[table] {
+		return []Difference{{Table: table, Kind: "table", Problem: "missing"}}
+	}
+
+	var diffs []Difference
+
+	// Columns
+	expectedColumns := make(map[string]bool)
+	for _, field := range sch.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		expectedColumns[field.DBName] = true
+
+		wantType := db.Dialector.DataTypeOf(field)
+		column, ok := live.columns[table][field.DBName]
+		if !ok {
+			diffs = append(diffs, Difference{Table: table, Kind: "column", Name: field.DBName, Problem: "missing", Detail: wantType})
+			continue
+		}
+		if normalizeType(wantType) != normalizeType(column.typeName) {
+			diffs = append(diffs, Difference{Table: table, Kind: "column", Name: field.DBName, Problem: "changed",
+				Detail: fmt.Sprintf("type is %s, model wants %s", column.typeName, wantType)})
+		}
+		if wantNotNull := field.NotNull || field.PrimaryKey; wantNotNull == column.nullable {
+			detail := "column is NOT NULL, model allows NULL"
+			if wantNotNull {
+				detail = "column allows NULL, model wants NOT NULL"
+			}
+			diffs = append(diffs, Difference{Table: table, Kind: "column", Name: field.DBName, Problem: "changed", Detail: detail})
+		}
+	}
+	for name := range live.columns[table] {
+		if !expectedColumns[name] {
+			diffs = append(diffs, Difference{Table: table, Kind: "column", Name: name, Problem: "unexpected"})
+		}
+	}
+
+	// Constraints, the way AutoMigrate names them
+	expectedConstraints := make(map[string]bool)
+	for name := range sch.ParseCheckConstraints() {
+		expectedConstraints[name] = true
+	}
+	for name := range sch.ParseUniqueConstraints() {
+		expectedConstraints[name] = true
+	}
+	for _, rel := range sch.Relationships.Relations {
+		if constraint := rel.ParseConstraint(); constraint != nil && constraint.Schema == sch {
+			expectedConstraints[constraint.Name] = true
+		}
+	}
+	diffs = append(diffs, compareNames(table, "constraint", expectedConstraints, live.constraints[table])...)
+
+	// Indexes; the primary key and unique constraints bring their own
+	expectedIndexes := map[string]bool{table + "_pkey": true}
+	for _, index := range sch.ParseIndexes() {
+		expectedIndexes[index.Name] = true
+	}
+	for name := range sch.ParseUniqueConstraints() {
+		expectedIndexes[name] = true
+	}
+	diffs = append(diffs, compareNames(table, "index", expectedIndexes, live.indexes[table])...)
+
+	return diffs
+}
+
+func compareNames(table, kind string, expected, actual map[string]bool) []Difference {
+	var diffs []Difference
+	for name := range expected {
+		if !actual[name] {
+			diffs = append(diffs, Difference{Table: table, Kind: kind, Name: name, Problem: "missing"})
+		}
+	}
+	for name := range actual {
+		if !expected[name] {
+			diffs = append(diffs, Difference{Table: table, Kind: kind, Name: name, Problem: "unexpected"})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+func inspect(db *gorm.DB) (*liveSchema, error) {
+	live := &liveSchema{
+		tables:      make(map[string]bool),
+		columns:     make(map[string]map[string]liveColumn),
+		indexes:     make(map[string]map[string]bool),
+		constraints: make(map[string]map[string]bool),
+	}
+
+	var tables []string
+	if err := db.Raw(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'`).Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	for _, table := range tables {
+		live.tables[table] = true
+	}
+
+	var columns []struct {
+		TableName  string
+		ColumnName string
+		UdtName    string
+		IsNullable string
+	}
+	if err := db.Raw(`SELECT table_name, column_name, udt_name, is_nullable FROM information_schema.columns
+		WHERE table_schema = current_schema()`).Scan(&columns).Error; err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+	for _, c := range columns {
+		if live.columns[c.TableName] == nil {
+			live.columns[c.TableName] = make(map[string]liveColumn)
+		}
+		live.columns[c.TableName][c.ColumnName] = liveColumn{typeName: c.UdtName, nullable: c.IsNullable == "YES"}
+	}
+
+	var indexes []struct {
+		Tablename string
+		Indexname string
+	}
+	if err := db.Raw(`SELECT tablename, indexname FROM pg_indexes WHERE schemaname = current_schema()`).Scan(&indexes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	for _, i := range indexes {
+		if live.indexes[i.Tablename] == nil {
+			live.indexes[i.Tablename] = make(map[string]bool)
+		}
+		live.indexes[i.Tablename][i.Indexname] = true
+	}
+
+	// Unique, foreign key and check constraints; primary keys are covered by
+	// their index and NOT NULL by the column comparison
+	var constraints []struct {
+		TableName string
+		Conname   string
+	}
+	if err := db.Raw(`SELECT c.conrelid::regclass::text AS table_name, c.conname FROM pg_constraint c
+		WHERE c.connamespace = current_schema()::regnamespace AND c.contype IN ('u', 'f', 'c')`).Scan(&constraints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list constraints: %w", err)
+	}
+	for _, c := range constraints {
+		table := strings.Trim(c.TableName, `"`)
+		if live.constraints[table] == nil {
+			live.constraints[table] = make(map[string]bool)
+		}
+		live.constraints[table][c.Conname] = true
+	}
+
+	return live, nil
+}
+
+var typeModifiers = regexp.MustCompile(`\(.*\)`)
+
+// typeAliases maps the type names GORM emits and information_schema reports
+// to one spelling.
+var typeAliases = map[string]string{
+	"bigint":                      "int8",
+	"bigserial":                   "int8",
+	"serial8":                     "int8",
+	"integer":                     "int4",
+	"int":                         "int4",
+	"serial":                      "int4",
+	"serial4":                     "int4",
+	"smallint":                    "int2",
+	"smallserial":                 "int2",
+	"serial2":                     "int2",
+	"boolean":                     "bool",
+	"decimal":                     "numeric",
+	"double precision":            "float8",
+	"real":                        "float4",
+	"character varying":           "varchar",
+	"character":                   "bpchar",
+	"char":                        "bpchar",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+	"time with time zone":         "timetz",
+	"time without time zone":      "time",
+}
+
+func normalizeType(t string) string {
+	t = strings.TrimSpace(typeModifiers.ReplaceAllString(strings.ToLower(t), ""))
+	if alias, ok := typeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// WriteDrift prints diffs as a readable diff, grouped by table.
+func WriteDrift(w io.Writer, diffs []Difference) error {
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintln(w, "No schema drift, the database matches the models")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Schema drift detected (%d differences)\n+ in models, missing in database\n- in database, not in models\n~ different\n", len(diffs)); err != nil {
+		return err
+	}
+	table := ""
+	for _, d := range diffs {
+		if d.Table != table {
+			table = d.Table
+			if _, err := fmt.Fprintf(w, "\n%s\n", table); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "  %s\n", d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
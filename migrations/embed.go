@@ -0,0 +1,12 @@
+// Package migrations embeds the Atlas migration directory and applies it
+// without the atlas CLI, reading and writing the same revision table.
+package migrations
+
+import "embed"
+
+// files holds the Atlas migration directory sql/, with its *.sql files and
+// atlas.sum, and the hand-written rollbacks under down/. The Go sources and
+// Atlas configuration next to them stay out of the binary.
+//
+//go:embed sql down
+var files embed.FS
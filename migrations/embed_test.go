@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/migrate"
+)
+
+func TestEmbeddedFiles(t *testing.T) {
+	err := fs.WalkDir(files, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		switch {
+		case strings.HasPrefix(name, "sql/"), strings.HasPrefix(name, "down/"):
+		default:
+			t.Errorf("%s is embedded, want only sql/ and down/", name)
+		}
+		if path.Ext(name) == ".go" {
+			t.Errorf("Go source %s is embedded", name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrate.Validate(dir); err != nil {
+		t.Errorf("embedded migrations do not match atlas.sum: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{migrate.HashFileName, true},
+		{"README.md", false},
+		{"embed.go", false},
+		{"atlas.hcl", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := dir.Open(tt.name)
+			if got := err == nil; got != tt.want {
+				t.Errorf("%s in Dir: %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/schema"
+)
+
+// revisionTable is where the atlas CLI records applied migrations. Using the
+// same table keeps `atlas migrate status` and the embedded runner in agreement.
+var revisionTable = &migrate.TableIdent{Schema: "atlas_schema_revisions", Name: "atlas_schema_revisions"}
+
+const createRevisionTable = `CREATE SCHEMA IF NOT EXISTS "atlas_schema_revisions";
+CREATE TABLE IF NOT EXISTS "atlas_schema_revisions"."atlas_schema_revisions" (
+	"version" character varying NOT NULL,
+	"description" character varying NOT NULL,
+	"type" bigint NOT NULL DEFAULT 2,
+	"applied" bigint NOT NULL DEFAULT 0,
+	"total" bigint NOT NULL DEFAULT 0,
+	"executed_at" timestamptz NOT NULL,
+	"execution_time" bigint NOT NULL,
+	"error" text NULL,
+	"error_stmt" text NULL,
+	"hash" character varying NOT NULL,
+	"partial_hashes" jsonb NULL,
+	"operator_version" character varying NOT NULL,
+	PRIMARY KEY ("version")
+)`
+
+// revisions implements migrate.RevisionReadWriter on the Atlas revision table.
+type revisions struct {
+	conn schema.ExecQuerier
+}
+
+func (r *revisions) Ident() *migrate.TableIdent {
+	return revisionTable
+}
+
+func (r *revisions) init(ctx context.Context) error {
+	_, err := r.conn.ExecContext(ctx, createRevisionTable)
+	return err
+}
+
+// ReadRevisions returns the applied revisions ordered by version, skipping the
+// bookkeeping rows Atlas stores under versions starting with a dot.
+func (r *revisions) ReadRevisions(ctx context.Context) ([]*migrate.Revision, error) {
+	rows, err := r.conn.QueryContext(ctx, `SELECT version, description, type, applied, total, executed_at,
+		execution_time, COALESCE(error, ''), COALESCE(error_stmt, ''), hash, partial_hashes, operator_version
+		FROM "atlas_schema_revisions"."atlas_schema_revisions"
+		WHERE version NOT LIKE '.%' ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revs []*migrate.Revision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, rev)
+	}
+	return revs, rows.Err()
+}
+
+func (r *revisions) ReadRevision(ctx context.Context, version string) (*migrate.Revision, error) {
+	rows, err := r.conn.QueryContext(ctx, `SELECT version, description, type, applied, total, executed_at,
+		execution_time, COALESCE(error, ''), COALESCE(error_stmt, ''), hash, partial_hashes, operator_version
+		FROM "atlas_schema_revisions"."atlas_schema_revisions" WHERE version = $1`, version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, migrate.ErrRevisionNotExist
+	}
+	return scanRevision(rows)
+}
+
+func (r *revisions) WriteRevision(ctx context.Context, rev *migrate.Revision) error {
+	var partialHashes []byte
+	if len(rev.PartialHashes) > 0 {
+		var err error
+		if partialHashes, err = json.Marshal(rev.PartialHashes); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.conn.ExecContext(ctx, `INSERT INTO "atlas_schema_revisions"."atlas_schema_revisions"
+		(version, description, type, applied, total, executed_at, execution_time, error, error_stmt, hash, partial_hashes, operator_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12)
+		ON CONFLICT (version) DO UPDATE SET
+			description = EXCLUDED.description, type = EXCLUDED.type, applied = EXCLUDED.applied,
+			total = EXCLUDED.total, executed_at = EXCLUDED.executed_at, execution_time = EXCLUDED.execution_time,
+			error = EXCLUDED.error, error_stmt = EXCLUDED.error_stmt, hash = EXCLUDED.hash,
+			partial_hashes = EXCLUDED.partial_hashes, operator_version = EXCLUDED.operator_version`,
+		rev.Version, rev.Description, int64(rev.Type), rev.Applied, rev.Total, rev.ExecutedAt,
+		int64(rev.ExecutionTime), rev.Error, rev.ErrorStmt, rev.Hash, partialHashes, rev.OperatorVersion,
+	)
+	return err
+}
+
+func (r *revisions) DeleteRevision(ctx context.Context, version string) error {
+	_, err := r.conn.ExecContext(ctx, `DELETE FROM "atlas_schema_revisions"."atlas_schema_revisions" WHERE version = $1`, version)
+	return err
+}
+
+func scanRevision(rows *sql.Rows) (*migrate.Revision, error) {
+	var (
+		rev           migrate.Revision
+		revType       int64
+		executionTime int64
+		partialHashes []byte
+	)
+	if err := rows.Scan(&rev.Version, &rev.Description, &revType, &rev.Applied, &rev.Total, &rev.ExecutedAt,
+		&executionTime, &rev.Error, &rev.ErrorStmt, &rev.Hash, &partialHashes, &rev.OperatorVersion); err != nil {
+		return nil, err
+	}
+	rev.Type = migrate.RevisionType(revType)
+	rev.ExecutionTime = time.Duration(executionTime)
+	if len(partialHashes) > 0 {
+		if err := json.Unmarshal(partialHashes, &rev.PartialHashes); err != nil {
+			return nil, errors.New("invalid partial_hashes in revision " + rev.Version)
+		}
+	}
+	return &rev, nil
+}
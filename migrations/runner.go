@@ -0,0 +1,376 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"ariga.io/atlas/sql/postgres"
+	"ariga.io/atlas/sql/schema"
+)
+
+// lockName identifies the advisory lock held while migrating, so instances
+// started together apply migrations one at a time.
+const lockName = "go-fiber-api:migrate"
+
+const operatorVersion = "go-fiber-api migrate"
+
+// Migration is the state of one migration file in the database.
+type Migration struct {
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	// State is "applied", "pending", "partial" or "failed"
+	State      string     `json:"state"`
+	ExecutedAt *time.Time `json:"executed_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Reversible bool       `json:"reversible"`
+}
+
+// Runner applies the embedded migrations to a database.
+type Runner struct {
+	db  *sql.DB
+	dir migrate.Dir
+}
+
+func NewRunner(db *sql.DB) (*Runner, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{db: db, dir: dir}, nil
+}
+
+// Dir returns the embedded migrations in the layout Atlas reads: the *.sql
+// files and atlas.sum of sql/.
+func Dir() (migrate.Dir, error) {
+	dir := &migrate.MemDir{}
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (path.Ext(name) != ".sql" && name != migrate.HashFileName) {
+			continue
+		}
+		content, err := files.ReadFile("sql/" + name)
+		if err != nil {
+			return nil, err
+		}
+		if err := dir.WriteFile(name, content); err != nil {
+			return nil, err
+		}
+	}
+	return dir, nil
+}
+
+// LatestVersion returns the version of the newest embedded migration, or an
+// empty string when there are none.
+func LatestVersion() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	migrationFiles, err := dir.Files()
+	if err != nil || len(migrationFiles) == 0 {
+		return "", err
+	}
+	return migrationFiles[len(migrationFiles)-1].Version(), nil
+}
+
+// Validate checks the migration files against atlas.sum and that every down
+// migration belongs to an existing migration.
+func (r *Runner) Validate() error {
+	if err := migrate.Validate(r.dir); err != nil {
+		return fmt.Errorf("migration directory does not match atlas.sum, run `atlas migrate hash` after editing migrations: %w", err)
+	}
+
+	migrationFiles, err := r.dir.Files()
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(migrationFiles))
+	for _, f := range migrationFiles {
+		names[f.Name()] = true
+	}
+
+	downFiles, err := fs.Glob(files, "down/*.sql")
+	if err != nil {
+		return err
+	}
+	for _, name := range downFiles {
+		if !names[path.Base(name)] {
+			return fmt.Errorf("down migration %s has no matching migration", name)
+		}
+	}
+	return nil
+}
+
+// Up applies up to n pending migrations, all of them when n is zero, and
+// returns the applied versions. On a database that already has tables but
+// no revisions, baseline names the version the schema is already at.
+func (r *Runner) Up(ctx context.Context, n int, baseline string) ([]string, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	revs := &revisions{conn: r.db}
+	if err := revs.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create revision table: %w", err)
+	}
+
+	// Pending also records the baseline revision on first use
+	executor, err := r.executor(r.db, revs, baseline)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := executor.Pending(ctx)
+	if errors.Is(err, migrate.ErrNoPendingFiles) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 && n < len(pending) {
+		pending = pending[:n]
+	}
+
+	var applied []string
+	for _, file := range pending {
+		if err := r.apply(ctx, file); err != nil {
+			return applied, err
+		}
+		applied = append(applied, file.Version())
+	}
+	return applied, nil
+}
+
+// apply runs one migration file and its revision bookkeeping in a
+// transaction. A failure is recorded on the revision after rolling back, as
+// the atlas CLI does, so it shows up in status and health checks.
+func (r *Runner) apply(ctx context.Context, file migrate.File) error {
+	slog.Info("Applying migration", "version", file.Version(), "description", file.Desc())
+	start := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	executor, err := r.executor(tx, &revisions{conn: tx}, "")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if execErr := executor.Execute(ctx, file); execErr != nil {
+		tx.Rollback()
+		if err := r.recordFailure(ctx, file, start, execErr); err != nil {
+			return errors.Join(execErr, err)
+		}
+		return execErr
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	slog.Info("Applied migration", "version", file.Version(), "duration", time.Since(start))
+	return nil
+}
+
+func (r *Runner) recordFailure(ctx context.Context, file migrate.File, start time.Time, execErr error) error {
+	sum, err := r.dir.Checksum()
+	if err != nil {
+		return err
+	}
+	hash, err := sum.SumByName(file.Name())
+	if err != nil {
+		return err
+	}
+	stmts, err := file.Stmts()
+	if err != nil {
+		return err
+	}
+
+	rev := &migrate.Revision{
+		Version:         file.Version(),
+		Description:     file.Desc(),
+		Type:            migrate.RevisionTypeExecute,
+		Total:           len(stmts),
+		ExecutedAt:      start,
+		ExecutionTime:   time.Since(start),
+		Error:           execErr.Error(),
+		Hash:            hash,
+		OperatorVersion: operatorVersion,
+	}
+	var stmtErr *migrate.StmtExecError
+	if errors.As(execErr, &stmtErr) {
+		rev.ErrorStmt = stmtErr.Stmt.Text
+		rev.Error = stmtErr.Err.Error()
+	}
+	return (&revisions{conn: r.db}).WriteRevision(ctx, rev)
+}
+
+// Down reverts the last n applied migrations using their files in down/ and
+// returns the reverted versions.
+func (r *Runner) Down(ctx context.Context, n int) ([]string, error) {
+	unlock, err := r.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	revs := &revisions{conn: r.db}
+	if err := revs.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create revision table: %w", err)
+	}
+	applied, err := revs.ReadRevisions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []string
+	for i := len(applied) - 1; i >= 0 && len(reverted) < n; i-- {
+		rev := applied[i]
+		if rev.Type == migrate.RevisionTypeBaseline {
+			return reverted, fmt.Errorf("cannot revert past baseline version %s", rev.Version)
+		}
+		if err := r.revert(ctx, rev); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, rev.Version)
+	}
+	return reverted, nil
+}
+
+func (r *Runner) revert(ctx context.Context, rev *migrate.Revision) error {
+	name := migrationName(rev.Version, rev.Description)
+	content, err := files.ReadFile("down/" + name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("migration %s is not reversible, add migrations/down/%s", rev.Version, name)
+	}
+	if err != nil {
+		return err
+	}
+	stmts, err := migrate.NewLocalFile(name, content).Stmts()
+	if err != nil {
+		return fmt.Errorf("failed to parse down migration %s: %w", name, err)
+	}
+
+	slog.Info("Reverting migration", "version", rev.Version, "description", rev.Description)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to revert %s: executing %q: %w", rev.Version, stmt, err)
+		}
+	}
+	if err := (&revisions{conn: tx}).DeleteRevision(ctx, rev.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Status lists every migration known to the directory or the database.
+func (r *Runner) Status(ctx context.Context) ([]Migration, error) {
+	migrationFiles, err := r.dir.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT to_regclass('atlas_schema_revisions.atlas_schema_revisions') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, err
+	}
+	var applied []*migrate.Revision
+	if exists {
+		if applied, err = (&revisions{conn: r.db}).ReadRevisions(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	byVersion := make(map[string]*Migration)
+	for _, f := range migrationFiles {
+		byVersion[f.Version()] = &Migration{Version: f.Version(), Description: f.Desc(), State: "pending"}
+	}
+	for _, rev := range applied {
+		m, ok := byVersion[rev.Version]
+		if !ok {
+			m = &Migration{Version: rev.Version, Description: rev.Description}
+			byVersion[rev.Version] = m
+		}
+		executedAt := rev.ExecutedAt
+		m.ExecutedAt = &executedAt
+		m.Error = rev.Error
+		switch {
+		case rev.Error != "":
+			m.State = "failed"
+		case rev.Applied < rev.Total:
+			m.State = "partial"
+		default:
+			m.State = "applied"
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		_, err := fs.Stat(files, "down/"+migrationName(m.Version, m.Description))
+		m.Reversible = err == nil
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func (r *Runner) executor(conn schema.ExecQuerier, revs *revisions, baseline string) (*migrate.Executor, error) {
+	drv, err := postgres.Open(conn)
+	if err != nil {
+		return nil, err
+	}
+	opts := []migrate.ExecutorOption{migrate.WithOperatorVersion(operatorVersion)}
+	if baseline != "" {
+		opts = append(opts, migrate.WithBaselineVersion(baseline))
+	}
+	return migrate.NewExecutor(drv, r.dir, revs, opts...)
+}
+
+// lock takes a session-level advisory lock on a dedicated connection,
+// waiting for any other instance that is migrating.
+func (r *Runner) lock(ctx context.Context) (func(), error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", lockName); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", lockName); err != nil {
+			slog.Error("Failed to release migration lock", "error", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+// migrationName is the file name Atlas gives a migration.
+func migrationName(version, description string) string {
+	if description == "" {
+		return version + ".sql"
+	}
+	return version + "_" + strings.TrimSuffix(description, ".sql") + ".sql"
+}
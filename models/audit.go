@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Admin actions recorded in the audit log.
+const (
+	AuditUserSuspended       = "user.suspended"
+	AuditUserUnsuspended     = "user.unsuspended"
+	AuditUserPasswordReset   = "user.password_reset"
+	AuditUserRoleChanged     = "user.role_changed"
+	AuditUserSessionsRevoked = "user.sessions_revoked"
+	AuditUserImpersonated    = "user.impersonated"
+	AuditUserDeleted         = "user.deleted"
+	AuditUserRestored        = "user.restored"
+	AuditUserPurged          = "user.purged"
+)
+
+// AuditLog records an action an admin took on a user, actor 0 standing for
+// the admin command. Neither user ID has a foreign key so entries outlive
+// purged users.
+type AuditLog struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ActorID      uint      `gorm:"not null;index" json:"actor_id"`
+	Action       string    `gorm:"not null;index" json:"action"`
+	TargetUserID uint      `gorm:"not null;index" json:"target_user_id"`
+	Reason       string    `gorm:"type:text" json:"reason,omitempty"`
+	IP           string    `json:"ip,omitempty"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+}
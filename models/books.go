@@ -8,6 +8,10 @@ type Book struct {
 	Publisher string `gorm:"not null" json:"publisher"`
 	Year      int    `gorm:"not null" json:"year"`
 	User      User   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+
+	// Denormalized from reviews, maintained incrementally by the review service
+	AverageRating float64 `gorm:"not null;default:0" json:"average_rating"`
+	ReviewCount   int     `gorm:"not null;default:0" json:"review_count"`
 }
 
 // func MigrateBooks(db *gorm.DB) error {
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	LoanStatusRequested = "requested"
+	LoanStatusApproved  = "approved"
+	LoanStatusDeclined  = "declined"
+	LoanStatusCancelled = "cancelled"
+	LoanStatusOverdue   = "overdue"
+	LoanStatusReturned  = "returned"
+)
+
+// Loan is a request to borrow a book and its outcome. A borrower has at most
+// one active (requested, approved or overdue) loan per book, enforced by
+// idx_loans_active_borrower.
+type Loan struct {
+	ID         uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	BookID     uint        `gorm:"not null;index;uniqueIndex:idx_loans_active_borrower,priority:1,where:status = 'requested' OR status = 'approved' OR status = 'overdue'" json:"book_id"`
+	OwnerID    uint        `gorm:"not null;index" json:"owner_id"`
+	BorrowerID uint        `gorm:"not null;index;uniqueIndex:idx_loans_active_borrower,priority:2" json:"borrower_id"`
+	Status     string      `gorm:"not null;index;default:requested" json:"status"`
+	Message    string      `gorm:"type:text" json:"message"`
+	ApprovedAt *time.Time  `json:"approved_at"`
+	DueDate    *time.Time  `gorm:"index" json:"due_date"`
+	ReturnedAt *time.Time  `json:"returned_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	Book       Book        `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"book,omitempty"`
+	Owner      User        `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
+	Borrower   User        `gorm:"foreignKey:BorrowerID" json:"borrower,omitempty"`
+	Events     []LoanEvent `gorm:"foreignKey:LoanID" json:"events,omitempty"`
+}
+
+// LoanEvent records every status change of a loan so both parties can see its history.
+type LoanEvent struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	LoanID     uint      `gorm:"not null;index" json:"loan_id"`
+	ActorID    *uint     `json:"actor_id"` // nil when the change was made by a scheduled job
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `gorm:"not null" json:"to_status"`
+	Note       string    `gorm:"type:text" json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+	Loan       Loan      `gorm:"foreignKey:LoanID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// All returns one instance of every model backed by a table. It feeds the
+// Atlas schema loader in cmd/atlas and the schema drift check, so add new
+// models here.
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&Book{},
+		&Review{},
+		&Shelf{},
+		&ShelfEntry{},
+		&Loan{},
+		&LoanEvent{},
+		&AuditLog{},
+		&Webhook{},
+		&WebhookDelivery{},
+		&WebhookAttempt{},
+		&OutboxEvent{},
+	}
+}
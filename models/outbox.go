@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OutboxEvent is an event recorded in the transaction of the change it
+// describes, kept until the relay has published it to every sink or given
+// up on it. Events of one aggregate are published in Sequence order.
+type OutboxEvent struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID       string `gorm:"not null;uniqueIndex" json:"event_id"`
+	Type          string `gorm:"not null" json:"type"`
+	AggregateType string `gorm:"not null;uniqueIndex:idx_outbox_events_aggregate_sequence,priority:1" json:"aggregate_type"`
+	AggregateID   uint   `gorm:"not null;uniqueIndex:idx_outbox_events_aggregate_sequence,priority:2" json:"aggregate_id"`
+	// Sequence numbers the events of an aggregate from 1, in commit order
+	Sequence uint `gorm:"not null;uniqueIndex:idx_outbox_events_aggregate_sequence,priority:3" json:"sequence"`
+	UserID   uint `gorm:"not null" json:"user_id"`
+	// Payload is the event data encoded as JSON
+	Payload    string    `gorm:"type:text;not null" json:"payload"`
+	OccurredAt time.Time `gorm:"not null" json:"occurred_at"`
+	// PublishedAt is set once every sink has the event
+	PublishedAt *time.Time `gorm:"index" json:"published_at"`
+	// DoneSinks is a comma-separated list of the sinks that have the event,
+	// skipped when it is retried
+	DoneSinks     string    `gorm:"type:text;not null;default:''" json:"done_sinks"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"not null" json:"next_attempt_at"`
+	LastError     string    `gorm:"type:text" json:"last_error"`
+	// DeadAt is set when the relay gives up on the event, after its last
+	// attempt failed. Later events of its aggregate are relayed regardless.
+	DeadAt    *time.Time `gorm:"index" json:"dead_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
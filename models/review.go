@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Review struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_reviews_user_book" json:"user_id"`
+	BookID    uint      `gorm:"not null;uniqueIndex:idx_reviews_user_book;index" json:"book_id"`
+	Rating    int       `gorm:"not null;check:rating >= 1 AND rating <= 5" json:"rating"`
+	Text      string    `gorm:"type:text" json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Book      Book      `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ReadingStatusWantToRead = "want_to_read"
+	ReadingStatusReading    = "reading"
+	ReadingStatusRead       = "read"
+)
+
+// DefaultShelves are created for every user on first access, one per reading status.
+var DefaultShelves = []struct {
+	Name   string
+	Status string
+}{
+	{Name: "Want to Read", Status: ReadingStatusWantToRead},
+	{Name: "Currently Reading", Status: ReadingStatusReading},
+	{Name: "Read", Status: ReadingStatusRead},
+}
+
+// IsDefaultShelfName reports whether name is that of a default shelf, ignoring case.
+func IsDefaultShelfName(name string) bool {
+	for _, def := range DefaultShelves {
+		if strings.EqualFold(name, def.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func IsValidReadingStatus(status string) bool {
+	switch status {
+	case ReadingStatusWantToRead, ReadingStatusReading, ReadingStatusRead:
+		return true
+	}
+	return false
+}
+
+type Shelf struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_shelves_user_name;uniqueIndex:idx_shelves_user_default,priority:1" json:"user_id"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_shelves_user_name" json:"name"`
+	IsDefault bool      `gorm:"not null;default:false" json:"is_default"`
+	Status    string    `gorm:"uniqueIndex:idx_shelves_user_default,priority:2,where:is_default" json:"status,omitempty"` // Reading status implied by a default shelf, empty for custom shelves
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+type ShelfEntry struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_shelf_entries_user_book" json:"user_id"`
+	BookID     uint       `gorm:"not null;uniqueIndex:idx_shelf_entries_user_book" json:"book_id"`
+	ShelfID    uint       `gorm:"not null;index" json:"shelf_id"`
+	Status     string     `gorm:"not null;default:want_to_read" json:"status"`
+	Progress   int        `gorm:"not null;default:0;check:progress >= 0 AND progress <= 100" json:"progress"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	User       User       `gorm:"foreignKey:UserID" json:"-"`
+	Book       Book       `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"book,omitempty"`
+	Shelf      Shelf      `gorm:"foreignKey:ShelfID;constraint:OnDelete:CASCADE" json:"-"`
+}
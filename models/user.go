@@ -6,6 +6,16 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
 type User struct {
 	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
 	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
@@ -14,4 +24,9 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Role       string     `gorm:"not null;default:user" json:"role"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// TokenVersion is embedded in issued tokens, incrementing it revokes all of the user's sessions
+	TokenVersion int `gorm:"not null;default:0" json:"-"`
 }
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// Webhook subscribes a URL to the events of its user's books and account,
+// or of every user when Global, which only admins may set.
+type Webhook struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	URL    string `gorm:"not null" json:"url"`
+	// Secret signs deliveries, it is only shown when the webhook is created
+	Secret string `gorm:"not null" json:"-"`
+	// Events is a comma-separated list of event types, empty for all of them
+	Events    string    `gorm:"type:text;not null;default:''" json:"events"`
+	Global    bool      `gorm:"not null;default:false" json:"global"`
+	Active    bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// WebhookDelivery is one event on its way to one webhook. It stays pending,
+// retried at NextAttemptAt, until it succeeds or runs out of attempts and is
+// dead-lettered.
+type WebhookDelivery struct {
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	// An event is delivered once per webhook, however often it is relayed
+	WebhookID uint   `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event,priority:1" json:"webhook_id"`
+	EventID   string `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event,priority:2" json:"event_id"`
+	Event     string `gorm:"not null" json:"event"`
+	// Payload is the exact body sent on every attempt
+	Payload        string           `gorm:"type:text;not null" json:"-"`
+	Status         string           `gorm:"not null;default:pending;index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int              `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time       `gorm:"index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	LastStatusCode int              `json:"last_status_code"`
+	LastError      string           `gorm:"type:text" json:"last_error"`
+	DeliveredAt    *time.Time       `json:"delivered_at"`
+	CreatedAt      time.Time        `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	Webhook        Webhook          `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+	Log            []WebhookAttempt `gorm:"foreignKey:DeliveryID" json:"log,omitempty"`
+}
+
+// WebhookAttempt records one HTTP request of a delivery.
+type WebhookAttempt struct {
+	ID         uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	DeliveryID uint `gorm:"not null;index" json:"delivery_id"`
+	// StatusCode is 0 when no response was received
+	StatusCode   int             `json:"status_code"`
+	Error        string          `gorm:"type:text" json:"error"`
+	ResponseBody string          `gorm:"type:text" json:"-"`
+	DurationMS   int64           `gorm:"not null" json:"duration_ms"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Delivery     WebhookDelivery `gorm:"foreignKey:DeliveryID;constraint:OnDelete:CASCADE" json:"-"`
+}
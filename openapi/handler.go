@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed docs.html
+var docsPage string
+
+// Handler serves the document as JSON. It is encoded once, up front.
+func Handler(doc *Document) (fiber.Handler, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx *fiber.Ctx) error {
+		ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return ctx.Send(body)
+	}, nil
+}
+
+// SpecURL names a document for the docs page.
+type SpecURL struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// DocsHandler serves a Swagger UI page for the documents at specs, the
+// first one selected. The UI itself is loaded from a CDN.
+func DocsHandler(title string, specs ...SpecURL) (fiber.Handler, error) {
+	urls, err := json.Marshal(specs)
+	if err != nil {
+		return nil, err
+	}
+	page := strings.NewReplacer("{{title}}", html.EscapeString(title), "{{urls}}", string(urls)).Replace(docsPage)
+	return func(ctx *fiber.Ctx) error {
+		ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return ctx.SendString(page)
+	}, nil
+}
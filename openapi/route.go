@@ -0,0 +1,315 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Auth is the authentication a route requires.
+type Auth int
+
+const (
+	Public Auth = iota
+	// Optional routes accept a bearer token and behave differently with one
+	Optional
+	Authenticated
+	// Admin routes need an authenticated user with the admin role
+	Admin
+)
+
+// Module documents the routes a module's SetupRoutes registers, under one
+// tag.
+type Module struct {
+	Tag         string
+	Description string
+	Routes      []Route
+}
+
+// Route documents one route. JSON responses are wrapped in the API's
+// envelope: Response is the type of "data", Meta of "meta", Links adds the
+// "links" object of related URLs, and every response carries an optional
+// "message".
+type Route struct {
+	Method string
+	// Path in Fiber syntax relative to the API prefix, e.g. "/books/:id".
+	// Parameters named id or ending in Id are integers.
+	Path        string
+	Summary     string
+	Description string
+	Auth        Auth
+
+	// Query is a struct whose query-tagged fields are query parameters,
+	// Params lists any others
+	Query  interface{}
+	Params []Parameter
+
+	// Request is the JSON body, OptionalBody when it may be omitted.
+	// RequestTypes lists raw body content types, FileField the field of a
+	// multipart upload.
+	Request      interface{}
+	OptionalBody bool
+	RequestTypes []string
+	FileField    string
+
+	// Status is the success status, 200 when zero. ResponseTypes replaces
+	// the JSON envelope with file downloads of the given content types.
+	Status        int
+	Response      interface{}
+	Meta          interface{}
+	Links         bool
+	ResponseTypes []string
+}
+
+// QueryParam documents a query parameter not covered by a Query struct.
+func QueryParam(name, typ, description string, enum ...string) Parameter {
+	schema := &Schema{Type: typ}
+	for _, value := range enum {
+		schema.Enum = append(schema.Enum, value)
+	}
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// Build generates the document for the modules' routes, mounted under
+// prefix. Registered routes under prefix must match the documented ones:
+// the returned error lists every route that is registered but not
+// documented or the other way round, and the document only includes routes
+// that exist.
+func Build(info Info, registered []fiber.Route, prefix string, modules ...Module) (*Document, error) {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: s.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	s.components["Error"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error":    {Type: "string"},
+			"trace_id": {Type: "string"},
+		},
+		Required: []string{"error"},
+	}
+
+	routes := map[string]bool{}
+	for _, route := range registered {
+		if route.Method == fiber.MethodHead || !strings.HasPrefix(route.Path, prefix) {
+			continue
+		}
+		routes[routeKey(route.Method, route.Path)] = true
+	}
+
+	var problems []string
+	for _, module := range modules {
+		doc.Tags = append(doc.Tags, Tag{Name: module.Tag, Description: module.Description})
+		for _, route := range module.Routes {
+			path := prefix + route.Path
+			key := routeKey(route.Method, path)
+			if !routes[key] {
+				problems = append(problems, "documented route is not registered: "+key)
+				continue
+			}
+			delete(routes, key)
+
+			openAPIPath := toOpenAPIPath(path)
+			if doc.Paths[openAPIPath] == nil {
+				doc.Paths[openAPIPath] = PathItem{}
+			}
+			op := s.operation(module.Tag, route, openAPIPath)
+			op.OperationID = operationID(route.Method, toOpenAPIPath(route.Path))
+			doc.Paths[openAPIPath][strings.ToLower(route.Method)] = op
+		}
+	}
+	for key := range routes {
+		problems = append(problems, "registered route is not documented: "+key)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return doc, errors.New(strings.Join(problems, "\n"))
+	}
+	return doc, nil
+}
+
+func (s *schemas) operation(tag string, route Route, path string) *Operation {
+	op := &Operation{
+		Tags:        []string{tag},
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   map[string]Response{},
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "Id") {
+			schema = &Schema{Type: "integer", Minimum: float(1)}
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	op.Parameters = append(op.Parameters, s.queryParams(route.Query)...)
+	op.Parameters = append(op.Parameters, route.Params...)
+
+	if body := s.requestBody(route); body != nil {
+		op.RequestBody = body
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	op.Responses[strconv.Itoa(status)] = s.successResponse(route, status)
+
+	errorResponse := func(status int) {
+		op.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     jsonContent(&Schema{Ref: "#/components/schemas/Error"}),
+		}
+	}
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		errorResponse(http.StatusBadRequest)
+	}
+	switch route.Auth {
+	case Optional:
+		op.Security = []map[string][]string{{}, {"bearerAuth": {}}}
+	case Authenticated:
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+		errorResponse(http.StatusUnauthorized)
+	case Admin:
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+		errorResponse(http.StatusUnauthorized)
+		errorResponse(http.StatusForbidden)
+	}
+	if strings.Contains(path, "{") {
+		errorResponse(http.StatusNotFound)
+	}
+	return op
+}
+
+func (s *schemas) queryParams(query interface{}) []Parameter {
+	if query == nil {
+		return nil
+	}
+	var params []Parameter
+	t := reflectStruct(query)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("query")
+		if name == "" || name == "-" {
+			continue
+		}
+		schema := s.schema(field.Type)
+		required := applyValidation(schema, field.Type, field.Tag.Get("validate"))
+		params = append(params, Parameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return params
+}
+
+func (s *schemas) requestBody(route Route) *RequestBody {
+	content := map[string]MediaType{}
+	if route.Request != nil {
+		content[fiber.MIMEApplicationJSON] = MediaType{Schema: s.of(route.Request)}
+	}
+	for _, contentType := range route.RequestTypes {
+		content[contentType] = MediaType{Schema: &Schema{Type: "string"}}
+	}
+	if route.FileField != "" {
+		content[fiber.MIMEMultipartForm] = MediaType{Schema: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				route.FileField: {Type: "string", ContentMediaType: "application/octet-stream"},
+			},
+			Required: []string{route.FileField},
+		}}
+	}
+	if len(content) == 0 {
+		return nil
+	}
+	return &RequestBody{Required: !route.OptionalBody, Content: content}
+}
+
+func (s *schemas) successResponse(route Route, status int) Response {
+	response := Response{Description: http.StatusText(status)}
+	if len(route.ResponseTypes) > 0 {
+		response.Content = map[string]MediaType{}
+		for _, contentType := range route.ResponseTypes {
+			response.Content[contentType] = MediaType{Schema: &Schema{Type: "string", ContentMediaType: contentType}}
+		}
+		return response
+	}
+
+	envelope := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"message": {Type: "string"}},
+	}
+	if route.Response != nil {
+		envelope.Properties["data"] = s.of(route.Response)
+		envelope.Required = append(envelope.Required, "data")
+	}
+	if route.Meta != nil {
+		envelope.Properties["meta"] = s.of(route.Meta)
+		envelope.Required = append(envelope.Required, "meta")
+	}
+	if route.Links {
+		envelope.Properties["links"] = &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string", Format: "uri-reference"}}
+		envelope.Required = append(envelope.Required, "links")
+	}
+	response.Content = jsonContent(envelope)
+	return response
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: schema}}
+}
+
+// routeKey identifies a route independently of trailing slashes, which
+// Fiber ignores by default.
+func routeKey(method, path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return method + " " + path
+}
+
+// toOpenAPIPath turns Fiber parameters (":id") into OpenAPI ones ("{id}").
+func toOpenAPIPath(path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimSuffix(segment[1:], "?") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationID derives a unique camel-case ID from the method and the path
+// relative to the API prefix, e.g. "getBooksById" for GET /books/{id}.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+			segment = strings.Trim(segment, "{}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,240 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemas generates schemas for Go types, registering named structs as
+// components so they are referenced rather than repeated.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// of returns the schema of the type of v, nil for a nil v.
+func (s *schemas) of(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return nullable(s.schema(t.Elem()))
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Custom JSON encodings can't be described by reflection
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem()), MinItems: integer(t.Len()), MaxItems: integer(t.Len())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.component(t)}
+	}
+	return &Schema{}
+}
+
+// component registers the named struct t and returns its component name.
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		// Same name in another package, e.g. models.User and dtos.User
+		name = pkgName(t) + name
+	}
+	s.names[t] = name
+	// Reserve the name before recursing, for self-referencing types
+	s.components[name] = nil
+	s.components[name] = s.object(t)
+	return name
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(object, t)
+	return object
+}
+
+func (s *schemas) addFields(object *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(object, ft)
+				continue
+			}
+		}
+
+		property := s.schema(field.Type)
+		if applyValidation(property, field.Type, field.Tag.Get("validate")) {
+			object.Required = append(object.Required, name)
+		}
+		object.Properties[name] = property
+	}
+}
+
+// jsonName returns the JSON name of a field, false for fields left out of
+// the encoding.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if !field.IsExported() || tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// applyValidation maps go-playground/validator rules onto schema
+// constraints and reports whether the value is required.
+func applyValidation(schema *Schema, t reflect.Type, rules string) bool {
+	if rules == "" {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Constraints go on the non-null branch of nullable values
+	target := schema
+	if len(schema.AnyOf) > 0 {
+		target = schema.AnyOf[0]
+	}
+
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				target.Enum = append(target.Enum, value)
+			}
+		case "min", "gte", "max", "lte", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			lower := name == "min" || name == "gte" || name == "len"
+			upper := name == "max" || name == "lte" || name == "len"
+			switch t.Kind() {
+			case reflect.String:
+				if lower {
+					target.MinLength = integer(int(n))
+				}
+				if upper {
+					target.MaxLength = integer(int(n))
+				}
+			case reflect.Slice, reflect.Array:
+				if lower {
+					target.MinItems = integer(int(n))
+				}
+				if upper {
+					target.MaxItems = integer(int(n))
+				}
+			default:
+				if lower {
+					target.Minimum = float(n)
+				}
+				if upper {
+					target.Maximum = float(n)
+				}
+			}
+		}
+	}
+	return required
+}
+
+// nullable allows null besides the values of schema.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" || schema.Type == nil {
+		return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+	}
+	if typ, ok := schema.Type.(string); ok {
+		schema.Type = []string{typ, "null"}
+	}
+	return schema
+}
+
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	name := path[strings.LastIndex(path, "/")+1:]
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func integer(i int) *int {
+	return &i
+}
+
+// reflectStruct returns the struct type of v or of what it points to.
+func reflectStruct(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
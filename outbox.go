@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rakibulbanna/go-fiber-postgres/config"
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	webhookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/webhook"
+	"github.com/rakibulbanna/go-fiber-postgres/outbox"
+	"gorm.io/gorm"
+)
+
+// brokerSubjectPrefix prefixes the subjects of the events published to the
+// broker, e.g. "bookapi.book.created".
+const brokerSubjectPrefix = "bookapi"
+
+// newOutboxRelay relays the outbox to the in-process bus, to webhooks and,
+// per OUTBOX_BROKER, to a local stand-in for a message broker. The
+// returned function closes the broker output.
+func newOutboxRelay(cfg *config.Config, db *gorm.DB, bus *events.Bus, webhooks *webhookModule.Service) (*outbox.Relay, func() error, error) {
+	relay := outbox.NewRelay(db, cfg.OutboxRetention, cfg.OutboxMaxAttempts)
+	relay.Register("bus", bus)
+	relay.Register("webhooks", webhooks)
+
+	closeBroker := func() error { return nil }
+	var w io.Writer
+	switch cfg.OutboxBroker {
+	case "none":
+		return relay, closeBroker, nil
+	case "stdout":
+		w = os.Stdout
+	case "file":
+		file, err := os.OpenFile(cfg.OutboxBrokerFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open outbox broker file: %w", err)
+		}
+		w, closeBroker = file, file.Close
+	default:
+		return nil, nil, fmt.Errorf("unknown outbox broker %q", cfg.OutboxBroker)
+	}
+
+	relay.Register("broker", outbox.NewBrokerSink(outbox.NewWriterBroker(w), brokerSubjectPrefix))
+	return relay, closeBroker, nil
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+)
+
+// Broker publishes messages to a message broker such as NATS or Kafka.
+// Messages with the same key must stay in order, as with a Kafka partition
+// key or a NATS subject per key.
+type Broker interface {
+	Publish(ctx context.Context, subject, key string, payload []byte) error
+}
+
+// BrokerSink publishes events to a Broker, on the subject prefix.type (e.g.
+// "bookapi.book.created") and keyed by aggregate (e.g. "book:12").
+type BrokerSink struct {
+	broker Broker
+	prefix string
+}
+
+func NewBrokerSink(broker Broker, prefix string) *BrokerSink {
+	return &BrokerSink{broker: broker, prefix: prefix}
+}
+
+// message is the payload of broker messages.
+type message struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Aggregate   string          `json:"aggregate"`
+	AggregateID uint            `json:"aggregate_id"`
+	UserID      uint            `json:"user_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(message{
+		ID:          event.ID,
+		Type:        event.Type,
+		Aggregate:   event.Aggregate(),
+		AggregateID: event.AggregateID,
+		UserID:      event.UserID,
+		OccurredAt:  event.OccurredAt,
+		Data:        data,
+	})
+	if err != nil {
+		return err
+	}
+
+	key := event.Aggregate() + ":" + strconv.FormatUint(uint64(event.AggregateID), 10)
+	return s.broker.Publish(ctx, s.prefix+"."+event.Type, key, payload)
+}
+
+// WriterBroker is a local stand-in for a message broker, for development
+// and tests without NATS or Kafka. It writes each message to w as a line
+// of JSON.
+type WriterBroker struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterBroker(w io.Writer) *WriterBroker {
+	return &WriterBroker{w: w}
+}
+
+func (b *WriterBroker) Publish(_ context.Context, subject, key string, payload []byte) error {
+	line, err := json.Marshal(struct {
+		Subject string          `json:"subject"`
+		Key     string          `json:"key"`
+		Payload json.RawMessage `json:"payload"`
+	}{subject, key, payload})
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, err = b.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,85 @@
+// Package outbox makes events as durable as the changes they describe.
+// Services record events with Write in the transaction of the change, and a
+// Relay publishes them to its sinks once the transaction has committed.
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"gorm.io/gorm"
+)
+
+// Write records events in the outbox as part of tx, so they are published
+// if and only if tx commits. It numbers the events of each aggregate after
+// the last one recorded, so call it once the aggregate's row is changed:
+// the row lock then keeps concurrent writers of an aggregate in commit
+// order, where they would otherwise conflict on the sequence.
+func Write(tx *gorm.DB, evs ...events.Event) error {
+	if len(evs) == 0 {
+		return nil
+	}
+
+	rows := make([]models.OutboxEvent, len(evs))
+	for i, event := range evs {
+		payload, err := json.Marshal(event.Data)
+		if err != nil {
+			return fmt.Errorf("encoding %s event: %w", event.Type, err)
+		}
+		rows[i] = models.OutboxEvent{
+			EventID:       event.ID,
+			Type:          event.Type,
+			AggregateType: event.Aggregate(),
+			AggregateID:   event.AggregateID,
+			UserID:        event.UserID,
+			Payload:       string(payload),
+			OccurredAt:    event.OccurredAt,
+			NextAttemptAt: event.OccurredAt,
+		}
+	}
+	if err := number(tx, rows); err != nil {
+		return err
+	}
+	return tx.CreateInBatches(&rows, 100).Error
+}
+
+type aggregateKey struct {
+	typ string
+	id  uint
+}
+
+// number sets the sequence of rows, continuing that of their aggregate.
+func number(tx *gorm.DB, rows []models.OutboxEvent) error {
+	last := make(map[aggregateKey]uint)
+	for i := range rows {
+		key := aggregateKey{rows[i].AggregateType, rows[i].AggregateID}
+		sequence, ok := last[key]
+		if !ok {
+			if err := tx.Model(&models.OutboxEvent{}).
+				Where("aggregate_type = ? AND aggregate_id = ?", key.typ, key.id).
+				Select("COALESCE(MAX(sequence), 0)").
+				Scan(&sequence).Error; err != nil {
+				return fmt.Errorf("numbering %s events: %w", key.typ, err)
+			}
+		}
+		sequence++
+		rows[i].Sequence = sequence
+		last[key] = sequence
+	}
+	return nil
+}
+
+// toEvent restores an event from its outbox row, with its data as a
+// json.RawMessage.
+func toEvent(row *models.OutboxEvent) events.Event {
+	return events.Event{
+		ID:          row.EventID,
+		Type:        row.Type,
+		AggregateID: row.AggregateID,
+		UserID:      row.UserID,
+		OccurredAt:  row.OccurredAt.UTC(),
+		Data:        json.RawMessage(row.Payload),
+	}
+}
@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+	"gorm.io/gorm"
+)
+
+func TestWriteSequence(t *testing.T) {
+	db := storagetest.Open(t, &models.OutboxEvent{})
+
+	book := func(id uint) events.Event { return events.New(events.BookUpdated, id, 1, nil) }
+	user := func(id uint) events.Event { return events.New(events.UserCreated, id, id, nil) }
+
+	// The writes run in order, each in a transaction of its own
+	tests := []struct {
+		name          string
+		events        []events.Event
+		wantSequences []uint
+	}{
+		{"first event", []events.Event{book(1)}, []uint{1}},
+		{"after recorded events", []events.Event{book(1)}, []uint{2}},
+		{"several per aggregate", []events.Event{book(1), book(2), book(1)}, []uint{3, 1, 4}},
+		{"other aggregate type", []events.Event{user(1)}, []uint{1}},
+		{"none", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := db.Transaction(func(tx *gorm.DB) error { return Write(tx, tt.events...) }); err != nil {
+				t.Fatal(err)
+			}
+
+			var sequences []uint
+			for _, event := range tt.events {
+				var row models.OutboxEvent
+				if err := db.Where("event_id = ?", event.ID).First(&row).Error; err != nil {
+					t.Fatal(err)
+				}
+				sequences = append(sequences, row.Sequence)
+			}
+			if !reflect.DeepEqual(sequences, tt.wantSequences) {
+				t.Errorf("sequences %v, want %v", sequences, tt.wantSequences)
+			}
+		})
+	}
+}
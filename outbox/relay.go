@@ -0,0 +1,243 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sink receives the events of the outbox. Delivery is at least once: an
+// event is retried on the sinks that failed it, and one published but not
+// yet marked as such when the relay stops, or when its claim runs out, is
+// published again. Sinks should deduplicate by event ID.
+type Sink interface {
+	Publish(ctx context.Context, event events.Event) error
+}
+
+const (
+	// batchSize is the number of events claimed at once
+	batchSize = 100
+	// claimTimeout is how long other relays skip the events of a claimed
+	// batch, the sinks must be done with it by then
+	claimTimeout = time.Minute
+	// retryBackoff is the wait before retrying an event, doubled after
+	// each failed attempt up to maxBackoff
+	retryBackoff = time.Second
+	maxBackoff   = 5 * time.Minute
+	// pruneInterval is how often published events past retention are
+	// deleted
+	pruneInterval = time.Hour
+)
+
+// Relay publishes the events of the outbox to its sinks. Several relays
+// can run against the same database: they skip the events another relay
+// has claimed, and an event is only relayed once the earlier events of its
+// aggregate are published or dead.
+type Relay struct {
+	db          *gorm.DB
+	retention   time.Duration
+	maxAttempts int
+	names       []string
+	sinks       []Sink
+}
+
+// NewRelay returns a relay without sinks. Published events are deleted
+// after retention, or kept when it is 0. An event still failing after
+// maxAttempts is dead-lettered: it is kept, but no longer relayed.
+func NewRelay(db *gorm.DB, retention time.Duration, maxAttempts int) *Relay {
+	return &Relay{db: db, retention: retention, maxAttempts: maxAttempts}
+}
+
+// Register adds a sink. Its name is recorded on the events it has, so it
+// must stay the same across restarts. Register sinks before Run.
+func (r *Relay) Register(name string, sink Sink) {
+	r.names = append(r.names, name)
+	r.sinks = append(r.sinks, sink)
+}
+
+// Run periodically relays pending events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Drain a backlog without waiting for the next tick
+			for {
+				relayed, err := r.RelayBatch(ctx)
+				if err != nil {
+					slog.Error("Outbox relay failed", "error", err)
+				}
+				if relayed < batchSize || ctx.Err() != nil {
+					break
+				}
+			}
+
+			if r.retention > 0 && time.Since(pruned) >= pruneInterval {
+				pruned = time.Now()
+				deleted, err := r.Prune(ctx)
+				if err != nil {
+					slog.Error("Outbox prune failed", "error", err)
+				} else if deleted > 0 {
+					slog.Info("Pruned published outbox events", "count", deleted)
+				}
+			}
+		}
+	}
+}
+
+// RelayBatch publishes a batch of due events, at most one per aggregate,
+// and returns how many it handled. The sinks are called after the claim
+// has committed, so a slow sink holds no locks or connection.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	due, err := r.claim(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("claiming outbox events: %w", err)
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, claimTimeout)
+	defer cancel()
+	// Outcomes are recorded on shutdown too, so events are not published
+	// again needlessly
+	db := r.db.WithContext(context.WithoutCancel(ctx))
+	var errs []error
+	for i := range due {
+		if err := db.Model(&due[i]).Updates(r.relay(publishCtx, &due[i])).Error; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return len(due), fmt.Errorf("recording outbox events: %w", err)
+	}
+	return len(due), nil
+}
+
+// claim selects the due events that are the earliest pending of their
+// aggregate, pending events being neither published nor dead, and counts an attempt and moves their next one past
+// claimTimeout so other relays skip them until then.
+func (r *Relay) claim(ctx context.Context) ([]models.OutboxEvent, error) {
+	var due []models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_events earlier
+				WHERE earlier.aggregate_type = outbox_events.aggregate_type
+				AND earlier.aggregate_id = outbox_events.aggregate_id
+				AND earlier.published_at IS NULL
+				AND earlier.dead_at IS NULL
+				AND earlier.sequence < outbox_events.sequence)`).
+			Order("id").
+			Limit(batchSize).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(due))
+		for i := range due {
+			ids[i] = due[i].ID
+			due[i].Attempts++
+		}
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": now.Add(claimTimeout),
+			}).Error
+	})
+	return due, err
+}
+
+// relay publishes a claimed event to the sinks that do not have it yet and
+// returns the updates recording the outcome. The event is dead-lettered
+// when a sink fails its last attempt.
+func (r *Relay) relay(ctx context.Context, row *models.OutboxEvent) map[string]interface{} {
+	event := toEvent(row)
+	done := splitSinks(row.DoneSinks)
+	attempts := row.Attempts
+	failure := "failed"
+	if attempts >= r.maxAttempts {
+		failure = "dead"
+	}
+
+	var errs []error
+	for i, sink := range r.sinks {
+		name := r.names[i]
+		if slices.Contains(done, name) {
+			continue
+		}
+		if err := sink.Publish(ctx, event); err != nil {
+			metrics.OutboxEventsRelayed.WithLabelValues(name, failure).Inc()
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		metrics.OutboxEventsRelayed.WithLabelValues(name, "succeeded").Inc()
+		done = append(done, name)
+	}
+
+	updates := map[string]interface{}{
+		"done_sinks": strings.Join(done, ","),
+	}
+	err := errors.Join(errs...)
+	switch {
+	case err != nil && failure == "dead":
+		updates["last_error"] = err.Error()
+		updates["dead_at"] = time.Now()
+		slog.ErrorContext(ctx, "Outbox event dead-lettered",
+			"event_id", row.EventID, "event", row.Type, "attempts", attempts, "error", err)
+	case err != nil:
+		updates["last_error"] = err.Error()
+		updates["next_attempt_at"] = time.Now().Add(backoff(attempts))
+		slog.WarnContext(ctx, "Outbox event not relayed, will retry",
+			"event_id", row.EventID, "event", row.Type, "attempts", attempts, "error", err)
+	default:
+		updates["published_at"] = time.Now()
+		updates["last_error"] = ""
+	}
+	return updates
+}
+
+// Prune deletes the events published longer than the retention ago.
+func (r *Relay) Prune(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("published_at < ?", time.Now().Add(-r.retention)).
+		Delete(&models.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("pruning outbox events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// backoff returns the wait before the retry following the given number of
+// attempts.
+func backoff(attempts int) time.Duration {
+	wait := retryBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+func splitSinks(sinks string) []string {
+	if sinks == "" {
+		return nil
+	}
+	return strings.Split(sinks, ",")
+}
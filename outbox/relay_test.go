@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+	"gorm.io/gorm"
+)
+
+// recordingSink records the IDs of the events it is given, failing those in
+// fail as many times as their count.
+type recordingSink struct {
+	published []string
+	fail      map[string]int
+}
+
+func (s *recordingSink) Publish(_ context.Context, event events.Event) error {
+	if s.fail[event.ID] > 0 {
+		s.fail[event.ID]--
+		return errors.New("unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+// newTestOutbox returns a database holding the events of two books. The
+// second event of book 1 has a lower ID than its first, as when it was
+// inserted first but committed last.
+func newTestOutbox(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := storagetest.Open(t, &models.OutboxEvent{})
+	due := time.Now().Add(-time.Second)
+	for _, row := range []models.OutboxEvent{
+		{EventID: "book1-2", Type: events.BookUpdated, AggregateType: "book", AggregateID: 1, Sequence: 2},
+		{EventID: "book1-1", Type: events.BookCreated, AggregateType: "book", AggregateID: 1, Sequence: 1},
+		{EventID: "book2-1", Type: events.BookCreated, AggregateType: "book", AggregateID: 2, Sequence: 1},
+	} {
+		row.Payload, row.OccurredAt, row.NextAttemptAt = "{}", due, due
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestRelayOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		fail map[string]int
+		// maxAttempts defaults to 10
+		maxAttempts int
+		// wantBatches are the events published by each batch, run until
+		// the outbox is drained, retries being due right away
+		wantBatches [][]string
+		wantDead    []string
+	}{
+		{
+			name:        "by sequence",
+			wantBatches: [][]string{{"book1-1", "book2-1"}, {"book1-2"}},
+		},
+		{
+			name:        "failed event holds back its aggregate",
+			fail:        map[string]int{"book1-1": 1},
+			wantBatches: [][]string{{"book2-1"}, {"book1-1"}, {"book1-2"}},
+		},
+		{
+			name:        "failed later event",
+			fail:        map[string]int{"book1-2": 2},
+			wantBatches: [][]string{{"book1-1", "book2-1"}, nil, nil, {"book1-2"}},
+		},
+		{
+			name:        "dead event releases its aggregate",
+			fail:        map[string]int{"book1-1": 5},
+			maxAttempts: 2,
+			wantBatches: [][]string{{"book2-1"}, nil, {"book1-2"}},
+			wantDead:    []string{"book1-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestOutbox(t)
+			sink := &recordingSink{fail: tt.fail}
+			maxAttempts := tt.maxAttempts
+			if maxAttempts == 0 {
+				maxAttempts = 10
+			}
+			relay := NewRelay(db, 0, maxAttempts)
+			relay.Register("test", sink)
+
+			var batches [][]string
+			for {
+				if err := db.Model(&models.OutboxEvent{}).Where("published_at IS NULL").
+					Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+					t.Fatal(err)
+				}
+				relayed, err := relay.RelayBatch(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if relayed == 0 {
+					break
+				}
+				batches = append(batches, sink.published)
+				sink.published = nil
+			}
+			if !reflect.DeepEqual(batches, tt.wantBatches) {
+				t.Errorf("batches %q, want %q", batches, tt.wantBatches)
+			}
+
+			var dead []string
+			if err := db.Model(&models.OutboxEvent{}).Where("dead_at IS NOT NULL").Pluck("event_id", &dead).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(dead) != 0 || len(tt.wantDead) != 0 {
+				if !reflect.DeepEqual(dead, tt.wantDead) {
+					t.Errorf("dead events %q, want %q", dead, tt.wantDead)
+				}
+			}
+		})
+	}
+}
+
+func TestRelayClaim(t *testing.T) {
+	db := newTestOutbox(t)
+	first := NewRelay(db, 0, 10)
+	second := NewRelay(db, 0, 10)
+	sink := &recordingSink{}
+	second.Register("test", sink)
+
+	claimed, err := first.claim(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("claimed %d events, want 2", len(claimed))
+	}
+
+	steps := []struct {
+		name string
+		// expire ends the claims of first
+		expire        bool
+		wantPublished []string
+	}{
+		{name: "claimed elsewhere", wantPublished: nil},
+		{name: "claim expired", expire: true, wantPublished: []string{"book1-1", "book2-1"}},
+		{name: "next in sequence", wantPublished: []string{"book1-2"}},
+	}
+	for _, step := range steps {
+		if step.expire {
+			if err := db.Model(&models.OutboxEvent{}).Where("published_at IS NULL").
+				Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := second.RelayBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sink.published, step.wantPublished) {
+			t.Errorf("%s: published %q, want %q", step.name, sink.published, step.wantPublished)
+		}
+		sink.published = nil
+	}
+
+	var row models.OutboxEvent
+	if err := db.Where("event_id = ?", "book1-1").First(&row).Error; err != nil {
+		t.Fatal(err)
+	}
+	if row.Attempts != 2 || row.PublishedAt == nil {
+		t.Errorf("book1-1 has %d attempts, published at %v, want 2 attempts and published", row.Attempts, row.PublishedAt)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	adminModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/admin"
+	authModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	bookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	loanModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/loan"
+	reviewModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/review"
+	shelfModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/shelf"
+	webhookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/webhook"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+)
+
+// API versions are served under /api/v<N>. Unversioned /api routes predate
+// versioning and serve v1.
+const (
+	apiPrefix         = "/api"
+	currentAPIVersion = 2
+)
+
+// apiVersions lists the served versions, oldest first.
+var apiVersions = []int{1, 2}
+
+func apiVersionPrefix(version int) string {
+	return apiPrefix + "/v" + strconv.Itoa(version)
+}
+
+func apiInfo(version int) openapi.Info {
+	return openapi.Info{
+		Title:       "Go Fiber Books API",
+		Version:     strconv.Itoa(version) + ".0.0",
+		Description: "Books, reviews, shelves, loans and webhooks. Protected routes take a bearer token from " + apiVersionPrefix(version) + "/auth/login.",
+	}
+}
+
+type controllers struct {
+	auth    *authModule.Controller
+	book    *bookModule.Controller
+	review  *reviewModule.Controller
+	shelf   *shelfModule.Controller
+	loan    *loanModule.Controller
+	admin   *adminModule.Controller
+	webhook *webhookModule.Controller
+}
+
+// setupAPI registers every API version under its prefix, and v1 under the
+// bare /api prefix too. deprecations holds the retired versions.
+func setupAPI(app *fiber.App, c controllers, authMiddleware *middleware.AuthMiddleware, deprecations map[int]*middleware.Deprecation) {
+	for _, version := range apiVersions {
+		prefix := apiVersionPrefix(version)
+		api := app.Group(prefix, middleware.APIVersion("v"+strconv.Itoa(version), prefix, deprecations[version]))
+		setupAPIRoutes(api, version, c, authMiddleware)
+	}
+
+	// Registered after the versioned groups, see middleware.APIVersion
+	legacy := app.Group(apiPrefix, middleware.APIVersion("v1", apiPrefix, deprecations[1]))
+	setupAPIRoutes(legacy, 1, c, authMiddleware)
+}
+
+// buildAPIDocs generates the OpenAPI document of each version from the
+// routes registered on the app. The error lists any mismatch between routes
+// and docs; the documents are usable regardless.
+func buildAPIDocs(routes []fiber.Route, deprecations map[int]*middleware.Deprecation) (map[int]*openapi.Document, error) {
+	docs := make(map[int]*openapi.Document, len(apiVersions))
+	var errs []error
+	for _, version := range apiVersions {
+		doc, err := openapi.Build(apiInfo(version), routes, apiVersionPrefix(version), apiDocs(version)...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("v%d: %w", version, err))
+		}
+		if deprecations[version] != nil {
+			doc.Deprecate()
+		}
+		docs[version] = doc
+	}
+	return docs, errors.Join(errs...)
+}
+
+// setupAPIRoutes registers the routes of one API version on api.
+func setupAPIRoutes(api fiber.Router, version int, c controllers, authMiddleware *middleware.AuthMiddleware) {
+	authModule.SetupRoutes(api, c.auth, version)
+	bookModule.SetupRoutes(api, c.book, authMiddleware, version)
+	reviewModule.SetupRoutes(api, c.review, authMiddleware)
+	shelfModule.SetupRoutes(api, c.shelf, authMiddleware)
+	loanModule.SetupRoutes(api, c.loan, authMiddleware)
+	adminModule.SetupRoutes(api, c.admin, authMiddleware)
+	webhookModule.SetupRoutes(api, c.webhook, authMiddleware)
+}
+
+// apiDocs documents the routes of setupAPIRoutes, in the order of the
+// OpenAPI document.
+func apiDocs(version int) []openapi.Module {
+	return []openapi.Module{
+		authModule.Docs(version),
+		bookModule.Docs(version),
+		reviewModule.Docs(),
+		shelfModule.Docs(),
+		loanModule.Docs(),
+		adminModule.Docs(),
+		webhookModule.Docs(),
+	}
+}
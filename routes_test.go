@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+)
+
+// TestOpenAPIMatchesRoutes fails when a route is added, removed or moved
+// without updating its module's Docs, or the other way round.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	app := fiber.New()
+	setupAPI(app, controllers{}, middleware.NewAuthMiddleware("", nil), nil)
+
+	specs, err := buildAPIDocs(app.GetRoutes(true), nil)
+	if err != nil {
+		t.Fatalf("OpenAPI documents and routes diverge:\n%v", err)
+	}
+
+	for version, spec := range specs {
+		operationIDs := map[string]string{}
+		for path, item := range spec.Paths {
+			for method, op := range item {
+				if other, ok := operationIDs[op.OperationID]; ok {
+					t.Errorf("v%d: operationId %q used by %s %s and %s", version, op.OperationID, method, path, other)
+				}
+				operationIDs[op.OperationID] = method + " " + path
+			}
+		}
+
+		if _, err := json.Marshal(spec); err != nil {
+			t.Fatalf("v%d: encoding OpenAPI document: %v", version, err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Package seed fills a development database with deterministic fixtures.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Password is shared by every seeded user so developers can log in as any
+// of them, unless Options.Password is set.
+const Password = "password123"
+
+// EmailDomain marks seeded users; their emails are user-0001@seed.example.com etc.
+const EmailDomain = "seed.example.com"
+
+// Profile is the volume of data to generate.
+type Profile struct {
+	Users int
+	Books int
+}
+
+// Profiles are the named volumes accepted by the seed command.
+var Profiles = map[string]Profile{
+	"small":    {Users: 5, Books: 20},
+	"demo":     {Users: 25, Books: 300},
+	"loadtest": {Users: 1000, Books: 100000},
+}
+
+// Options control a seed run.
+type Options struct {
+	Profile Profile
+	// Seed makes the generated data reproducible
+	Seed uint64
+	// Password is given to the new users, Password by default
+	Password string
+}
+
+// Result counts what a run inserted; rows that already existed are skipped.
+type Result struct {
+	UsersCreated int
+	BooksCreated int
+}
+
+// Run inserts the users and books of opts that are not in the database yet.
+// The same options always produce the same data, so repeated runs only fill
+// in what is missing.
+func Run(ctx context.Context, db *gorm.DB, opts Options) (*Result, error) {
+	db = db.WithContext(ctx)
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x5eed))
+	result := &Result{}
+
+	// One bcrypt hash for everyone, hashing thousands would take minutes
+	password := opts.Password
+	if password == "" {
+		password = Password
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	users := make([]models.User, opts.Profile.Users)
+	emails := make([]string, len(users))
+	for i := range users {
+		first, last := pick(rng, firstNames), pick(rng, lastNames)
+		emails[i] = fmt.Sprintf("user-%04d@%s", i+1, EmailDomain)
+		users[i] = models.User{Email: emails[i], Name: first + " " + last, Password: hash}
+	}
+
+	if len(users) == 0 {
+		return result, nil
+	}
+
+	// Books are generated before touching the database so the RNG sequence,
+	// and therefore the data, does not depend on what already exists
+	booksByOwner := make([][]models.Book, len(users))
+	for range opts.Profile.Books {
+		owner := rng.IntN(len(users))
+		booksByOwner[owner] = append(booksByOwner[owner], models.Book{
+			Title:     title(rng),
+			Author:    pick(rng, firstNames) + " " + pick(rng, lastNames),
+			Publisher: pick(rng, publishers),
+			Year:      1950 + rng.IntN(76),
+		})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		insert := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&users, 500)
+		if insert.Error != nil {
+			return fmt.Errorf("failed to create users: %w", insert.Error)
+		}
+		result.UsersCreated = int(insert.RowsAffected)
+
+		// Look the users up again, existing ones were not returned by the insert
+		var existing []models.User
+		if err := tx.Where("email IN ?", emails).Find(&existing).Error; err != nil {
+			return err
+		}
+		ids := make(map[string]uint, len(existing))
+		for _, u := range existing {
+			ids[u.Email] = u.ID
+		}
+
+		var counts []struct {
+			UserID uint
+			Count  int
+		}
+		if err := tx.Model(&models.Book{}).Select("user_id, count(*) AS count").
+			Joins("JOIN users ON users.id = books.user_id").
+			Where("users.email IN ?", emails).Group("user_id").Scan(&counts).Error; err != nil {
+			return err
+		}
+		owned := make(map[uint]int, len(counts))
+		for _, c := range counts {
+			owned[c.UserID] = c.Count
+		}
+
+		var books []models.Book
+		for i, ownerBooks := range booksByOwner {
+			id, ok := ids[emails[i]]
+			if !ok {
+				return fmt.Errorf("seeded user %s not found", emails[i])
+			}
+			for _, book := range ownerBooks[min(owned[id], len(ownerBooks)):] {
+				book.UserID = id
+				books = append(books, book)
+			}
+		}
+		if len(books) > 0 {
+			if err := tx.CreateInBatches(&books, 1000).Error; err != nil {
+				return fmt.Errorf("failed to create books: %w", err)
+			}
+		}
+		result.BooksCreated = len(books)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Truncate empties every model table and resets their id sequences.
+func Truncate(ctx context.Context, db *gorm.DB) error {
+	tables := make([]string, 0, len(models.All()))
+	for _, model := range models.All() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		tables = append(tables, db.Statement.Quote(stmt.Schema.Table))
+	}
+	return db.WithContext(ctx).Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE").Error
+}
+
+func pick(rng *rand.Rand, words []string) string {
+	return words[rng.IntN(len(words))]
+}
+
+func title(rng *rand.Rand) string {
+	switch rng.IntN(3) {
+	case 0:
+		return "The " + pick(rng, adjectives) + " " + pick(rng, nouns)
+	case 1:
+		return pick(rng, nouns) + " of " + pick(rng, places)
+	default:
+		return "A " + pick(rng, adjectives) + " " + pick(rng, nouns) + " in " + pick(rng, places)
+	}
+}
+
+var (
+	firstNames = []string{"Ada", "Alan", "Amara", "Bianca", "Chen", "Diego", "Elena", "Farah", "Grace", "Hiro",
+		"Ines", "Jonas", "Kofi", "Lena", "Mateo", "Nadia", "Omar", "Priya", "Quinn", "Rosa", "Sven", "Tariq", "Uma", "Yara"}
+	lastNames = []string{"Adeyemi", "Bauer", "Castillo", "Dubois", "Eriksen", "Fischer", "Garcia", "Haddad", "Ito",
+		"Jensen", "Kowalski", "Lindqvist", "Moreau", "Nakamura", "Okafor", "Petrov", "Rahman", "Silva", "Tanaka", "Novak"}
+	publishers = []string{"Penguin Random House", "HarperCollins", "Macmillan", "Hachette", "Simon & Schuster",
+		"Faber & Faber", "Bloomsbury", "Vintage", "Tor Books", "Orbit"}
+	adjectives = []string{"Silent", "Hidden", "Last", "Burning", "Forgotten", "Crimson", "Endless", "Broken",
+		"Golden", "Quiet", "Wandering", "Distant"}
+	nouns = []string{"River", "Garden", "Empire", "Lighthouse", "Cartographer", "Orchard", "Storm", "Archive",
+		"Mirror", "Voyage", "Kingdom", "Letters"}
+	places = []string{"Lisbon", "the North", "Glass Mountains", "Kyoto", "the Delta", "Winter", "Marrakesh",
+		"the Old Quarter", "Patagonia", "the Coast"}
+)
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/rakibulbanna/go-fiber-postgres/logging"
+	"github.com/rakibulbanna/go-fiber-postgres/seed"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+)
+
+// seedCommand implements "app seed [--profile small|demo|loadtest] [--truncate] [--force]".
+func seedCommand(args []string) {
+	profiles := make([]string, 0, len(seed.Profiles))
+	for name := range seed.Profiles {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	profileName := fs.String("profile", "small", "Data volume: "+strings.Join(profiles, ", "))
+	users := fs.Int("users", 0, "Number of users, overrides the profile")
+	books := fs.Int("books", 0, "Number of books, overrides the profile")
+	rngSeed := fs.Uint64("seed", 1, "Random seed, the same seed always generates the same data")
+	truncate := fs.Bool("truncate", false, "Empty all tables first (refused in production)")
+	force := fs.Bool("force", false, "Seed even when APP_ENV is production, with a random password")
+
+	cfg := loadConfig(fs, args)
+	logger, err := logging.SetupTo(os.Stderr, cfg.LogLevel, "text")
+	if err != nil {
+		logging.Fatal("Error setting up logging", err)
+	}
+
+	profile, ok := seed.Profiles[*profileName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown profile %q, use one of %s\n", *profileName, strings.Join(profiles, ", "))
+		os.Exit(2)
+	}
+	if *users > 0 {
+		profile.Users = *users
+	}
+	if *books > 0 {
+		profile.Books = *books
+	}
+	password, err := seedPassword(cfg.Env, *force, *truncate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := storage.NewConnection(ctx, storageConfig(cfg, logger))
+	if err != nil {
+		logging.Fatal("Error connecting to database", err)
+	}
+	defer storage.Close(db)
+
+	if *truncate {
+		if err := seed.Truncate(ctx, db); err != nil {
+			storage.Close(db)
+			logging.Fatal("Error truncating tables", err)
+		}
+		logger.Info("Tables truncated")
+	}
+
+	result, err := seed.Run(ctx, db, seed.Options{Profile: profile, Seed: *rngSeed, Password: password})
+	if err != nil {
+		storage.Close(db)
+		logging.Fatal("Error seeding database", err)
+	}
+
+	fmt.Printf("Created %d users and %d books (%s profile, seed %d)\n", result.UsersCreated, result.BooksCreated, *profileName, *rngSeed)
+	fmt.Printf("Log in as user-0001@%s with password %q\n", seed.EmailDomain, password)
+}
+
+// seedPassword returns the password of the users seeded in env. Production
+// is only seeded with --force, never truncated, and gets a random password
+// rather than the well-known development one.
+func seedPassword(env string, force, truncate bool) (string, error) {
+	if env != "production" {
+		return seed.Password, nil
+	}
+	if truncate {
+		return "", errors.New("--truncate is not allowed when APP_ENV is production")
+	}
+	if !force {
+		return "", errors.New("seeding is not allowed when APP_ENV is production, pass --force to seed anyway")
+	}
+	return rand.Text(), nil
+}
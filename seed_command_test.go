@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/seed"
+)
+
+func TestSeedPassword(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       string
+		force     bool
+		truncate  bool
+		wantErr   bool
+		wantFixed bool
+	}{
+		{name: "development", env: "development", wantFixed: true},
+		{name: "development truncate", env: "development", truncate: true, wantFixed: true},
+		{name: "production", env: "production", wantErr: true},
+		{name: "production truncate", env: "production", force: true, truncate: true, wantErr: true},
+		{name: "production forced", env: "production", force: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := seedPassword(tt.env, tt.force, tt.truncate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if fixed := password == seed.Password; fixed != tt.wantFixed {
+				t.Errorf("password %q, want the fixed one %v", password, tt.wantFixed)
+			}
+			if len(password) < 8 {
+				t.Errorf("password %q is too short", password)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"net"
+	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rakibulbanna/go-fiber-postgres/config"
+	"github.com/rakibulbanna/go-fiber-postgres/events"
+	adminModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/admin"
+	authModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/auth"
+	bookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/book"
+	graphqlModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/graphql"
+	healthModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/health"
+	loanModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/loan"
+	reviewModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/review"
+	shelfModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/shelf"
+	webhookModule "github.com/rakibulbanna/go-fiber-postgres/internal/modules/webhook"
+	"github.com/rakibulbanna/go-fiber-postgres/logging"
+	"github.com/rakibulbanna/go-fiber-postgres/metrics"
+	"github.com/rakibulbanna/go-fiber-postgres/middleware"
+	"github.com/rakibulbanna/go-fiber-postgres/openapi"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+// serve runs the HTTP server until SIGINT or SIGTERM.
+func serve(args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg := loadConfig(flag.NewFlagSet("serve", flag.ExitOnError), args)
+
+	// Logging
+	logger, err := logging.Setup(cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		logging.Fatal("Error setting up logging", err)
+	}
+
+	// Tracing
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		File:        cfg.TracingFile,
+		ServiceName: cfg.ServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logging.Fatal("Error setting up tracing", err)
+	}
+
+	// Database connection
+	db, err := openDatabase(ctx, cfg, logger)
+	if err != nil {
+		logging.Fatal("Error connecting to database", err)
+	}
+	if cfg.MigrateOnStart {
+		if err := migrateUp(ctx, db, 0, ""); err != nil {
+			logging.Fatal("Error applying migrations", err)
+		}
+	}
+	if cfg.SchemaDriftCheck {
+		warnSchemaDrift(ctx, db)
+	}
+	replicas, err := openReplicas(ctx, cfg, logger, db)
+	if err != nil {
+		logging.Fatal("Error connecting to read replicas", err)
+	}
+
+	// Initialize modules
+	authService := authModule.NewService(db, cfg.JWTSecret)
+	authController := authModule.NewController(authService)
+
+	// Initialize middleware
+	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTSecret, authService)
+
+	adminService := adminModule.NewService(db, cfg.JWTSecret, cfg.AdminImpersonationTTL, cfg.AdminDeleteGracePeriod)
+	adminController := adminModule.NewController(adminService)
+
+	bookService := bookModule.NewService(db, replicas)
+	bookController := bookModule.NewController(bookService)
+
+	graphqlController := graphqlModule.NewController(bookService, authService)
+
+	reviewService := reviewModule.NewService(db)
+	reviewController := reviewModule.NewController(reviewService)
+
+	shelfService := shelfModule.NewService(db)
+	shelfController := shelfModule.NewController(shelfService)
+
+	loanService := loanModule.NewService(db)
+	loanController := loanModule.NewController(loanService)
+
+	webhookService := webhookModule.NewService(db, webhookConfig(cfg))
+	webhookController := webhookModule.NewController(webhookService)
+
+	// Events recorded by the services above are relayed from the outbox
+	bus := events.NewBus()
+	bus.Subscribe(func(ctx context.Context, event events.Event) error {
+		metrics.DomainEvents.WithLabelValues(event.Type).Inc()
+		return nil
+	})
+	relay, closeBroker, err := newOutboxRelay(cfg, db, bus, webhookService)
+	if err != nil {
+		logging.Fatal("Error setting up outbox relay", err)
+	}
+
+	healthController := healthModule.NewController(
+		healthModule.NewDatabaseChecker(db),
+		healthModule.NewMigrationChecker(db),
+	)
+
+	// Background jobs, stopped through jobsCtx on shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		loanService.RunOverdueJob(jobsCtx, cfg.LoanOverdueCheckInterval)
+	}()
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		replicas.RunHealthChecks(jobsCtx)
+	}()
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		webhookService.RunDeliveryJob(jobsCtx, cfg.WebhookDeliveryInterval)
+	}()
+
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		relay.Run(jobsCtx, cfg.OutboxRelayInterval)
+	}()
+
+	// Initialize Fiber app
+	app := fiber.New(fiber.Config{
+		// The ASCII banner would break line-oriented JSON logs
+		DisableStartupMessage: cfg.LogFormat == "json",
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			code := fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				code = e.Code
+			}
+			body := fiber.Map{
+				"error": err.Error(),
+			}
+			if traceID := tracing.TraceID(c.UserContext()); traceID != "" {
+				body["trace_id"] = traceID
+			}
+			return c.Status(code).JSON(body)
+		},
+	})
+
+	// Middleware
+	app.Use(recover.New())
+	app.Use(requestid.New())
+	app.Use(middleware.Tracing)
+	app.Use(middleware.RequestLogger)
+	app.Use(middleware.Metrics)
+
+	// Setup routes
+	healthModule.SetupRoutes(app, healthController)
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
+	deprecations := map[int]*middleware.Deprecation{
+		1: {
+			At:              cfg.APIV1DeprecatedAt,
+			Sunset:          cfg.APIV1SunsetAt,
+			SuccessorPrefix: apiVersionPrefix(currentAPIVersion),
+			URL:             cfg.APIV1DeprecationURL,
+		},
+	}
+	setupAPI(app, controllers{
+		auth:    authController,
+		book:    bookController,
+		review:  reviewController,
+		shelf:   shelfController,
+		loan:    loanController,
+		admin:   adminController,
+		webhook: webhookController,
+	}, authMiddleware, deprecations)
+	graphqlModule.SetupRoutes(app, graphqlController, authMiddleware)
+
+	// API documentation, checked against the routes registered above
+	specs, err := buildAPIDocs(app.GetRoutes(true), deprecations)
+	if err != nil {
+		slog.Error("OpenAPI documents do not match the registered routes", "error", err)
+	}
+	var specURLs []openapi.SpecURL
+	for _, version := range slices.Backward(apiVersions) {
+		handler, err := openapi.Handler(specs[version])
+		if err != nil {
+			logging.Fatal("Error encoding OpenAPI document", err)
+		}
+		url := "/openapi/v" + strconv.Itoa(version) + ".json"
+		app.Get(url, handler)
+		if version == currentAPIVersion {
+			app.Get("/openapi.json", handler)
+		}
+		specURLs = append(specURLs, openapi.SpecURL{Name: "v" + strconv.Itoa(version), URL: url})
+	}
+	docsHandler, err := openapi.DocsHandler(apiInfo(currentAPIVersion).Title, specURLs...)
+	if err != nil {
+		logging.Fatal("Error rendering API docs page", err)
+	}
+	app.Get("/docs", docsHandler)
+
+	// gRPC server alongside the HTTP API
+	var grpcServer *grpc.Server
+	var grpcHealth *health.Server
+	if cfg.GRPCPort != "" {
+		grpcServer, grpcHealth = newGRPCServer(authMiddleware, authService, bookService)
+	}
+
+	// Start server
+	serverErr := make(chan error, 2)
+	go func() {
+		slog.Info("Server starting", "port", cfg.Port)
+		serverErr <- app.Listen(":" + cfg.Port)
+	}()
+	if grpcServer != nil {
+		listener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			logging.Fatal("Error starting gRPC server", err)
+		}
+		go func() {
+			slog.Info("gRPC server starting", "port", cfg.GRPCPort)
+			serverErr <- grpcServer.Serve(listener)
+		}()
+		setGRPCServing(grpcHealth, true)
+	}
+	healthController.SetReady(true)
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			logging.Fatal("Error starting server", err)
+		}
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Fail readiness first so load balancers stop routing here, then drain
+	slog.Info("Shutting down, draining in-flight requests", "timeout", cfg.ShutdownTimeout)
+	healthController.SetReady(false)
+	if grpcHealth != nil {
+		grpcHealth.Shutdown()
+	}
+	time.Sleep(cfg.ShutdownDelay)
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcServer != nil {
+			stopGRPC(grpcServer, cfg.ShutdownTimeout)
+		}
+	}()
+	if err := app.ShutdownWithTimeout(cfg.ShutdownTimeout); err != nil {
+		slog.Error("Error draining server", "error", err)
+	}
+	<-grpcStopped
+
+	stopJobs()
+	jobs.Wait()
+	if err := closeBroker(); err != nil {
+		slog.Error("Error closing outbox broker", "error", err)
+	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("Error flushing traces", "error", err)
+	}
+
+	if err := replicas.Close(); err != nil {
+		slog.Error("Error closing read replicas", "error", err)
+	}
+	if err := storage.Close(db); err != nil {
+		slog.Error("Error closing database", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// webhookConfig maps the application configuration to the webhook module.
+func webhookConfig(cfg *config.Config) webhookModule.Config {
+	return webhookModule.Config{
+		Timeout:      cfg.WebhookTimeout,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		RetryBackoff: cfg.WebhookRetryBackoff,
+		// Receivers are often local servers during development
+		AllowHTTP:    cfg.IsDevelopment(),
+		AllowPrivate: cfg.IsDevelopment(),
+	}
+}
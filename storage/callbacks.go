@@ -0,0 +1,25 @@
+package storage
+
+import "gorm.io/gorm"
+
+// Hook registers callbacks right before or after one GORM operation, by
+// name, e.g. "metrics:before_query".
+type Hook struct {
+	Operation string
+	Before    func(name string, fn func(*gorm.DB)) error
+	After     func(name string, fn func(*gorm.DB)) error
+}
+
+// Hooks returns the hooks of every GORM operation of db, for plugins that
+// instrument them all.
+func Hooks(db *gorm.DB) []Hook {
+	cb := db.Callback()
+	return []Hook{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+}
@@ -0,0 +1,50 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+	"gorm.io/gorm"
+)
+
+func TestHooks(t *testing.T) {
+	db := storagetest.Open(t, &models.User{})
+
+	calls := make(map[string][]string)
+	for _, hook := range storage.Hooks(db) {
+		operation := hook.Operation
+		if err := hook.Before("test:before_"+operation, func(*gorm.DB) { calls[operation] = append(calls[operation], "before") }); err != nil {
+			t.Fatal(err)
+		}
+		if err := hook.After("test:after_"+operation, func(*gorm.DB) { calls[operation] = append(calls[operation], "after") }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	user := models.User{Email: "reader@example.com", Password: "x", Name: "Reader"}
+	tests := []struct {
+		operation string
+		run       func() error
+	}{
+		{"create", func() error { return db.Create(&user).Error }},
+		{"query", func() error { return db.First(&models.User{}, user.ID).Error }},
+		{"update", func() error { return db.Model(&user).Update("name", "Writer").Error }},
+		{"row", func() error { return db.Model(&models.User{}).Select("name").Row().Err() }},
+		{"raw", func() error { return db.Exec("UPDATE users SET name = ?", "Editor").Error }},
+		{"delete", func() error { return db.Delete(&user).Error }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			clear(calls)
+			if err := tt.run(); err != nil {
+				t.Fatal(err)
+			}
+			if got := calls[tt.operation]; len(got) != 2 || got[0] != "before" || got[1] != "after" {
+				t.Errorf("callbacks %v, want [before after]", got)
+			}
+		})
+	}
+}
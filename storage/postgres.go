@@ -1,28 +1,161 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 type Config struct {
+	// URL is a postgres:// connection string; when set it replaces the
+	// individual connection fields below.
+	URL      string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
+	Logger   logger.Interface // GORM's default logger when nil
+
+	// Pool settings, zero keeps the database/sql default
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// StatementTimeout aborts queries running longer than this, zero disables it
+	StatementTimeout time.Duration
+
+	// PrepareStatements caches prepared statements per connection. Disable it
+	// behind poolers in transaction mode, such as PgBouncer, which cannot keep
+	// server-side statements across transactions.
+	PrepareStatements bool
+
+	// ConnectRetries is how many times to retry the initial connection,
+	// waiting ConnectBackoff and doubling it after each attempt.
+	ConnectRetries int
+	ConnectBackoff time.Duration
 }
 
-func NewConnection(config Config) (*gorm.DB, error) {
+// maxConnectBackoff caps the wait between connection attempts.
+const maxConnectBackoff = 30 * time.Second
+
+// DSN returns the connection string passed to the driver.
+func (c Config) DSN() (string, error) {
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid database URL: %w", err)
+		}
+		if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+			return "", fmt.Errorf("invalid database URL scheme %q", u.Scheme)
+		}
+		if c.StatementTimeout > 0 {
+			query := u.Query()
+			query.Set("statement_timeout", fmt.Sprint(c.StatementTimeout.Milliseconds()))
+			u.RawQuery = query.Encode()
+		}
+		return u.String(), nil
+	}
+
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
+		c.Host, c.Port, c.User, quoteDSNValue(c.Password), c.DBName, c.SSLMode,
 	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if c.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", c.StatementTimeout.Milliseconds())
+	}
+	return dsn, nil
+}
+
+// Database returns the name of the database the config connects to.
+func (c Config) Database() string {
+	if c.URL != "" {
+		if u, err := url.Parse(c.URL); err == nil {
+			return strings.TrimPrefix(u.Path, "/")
+		}
+	}
+	return c.DBName
+}
+
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// NewConnection opens the connection pool, retrying with exponential backoff
+// while the database is not reachable yet. It gives up early when ctx is done.
+func NewConnection(ctx context.Context, config Config) (*gorm.DB, error) {
+	backoff := config.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		db, err := open(config, true)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= config.ConnectRetries {
+			return nil, fmt.Errorf("failed to connect after %d attempts: %w", attempt+1, err)
+		}
+
+		slog.Warn("Database not reachable, retrying", "attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// open creates the pool, pinging the database first when ping is set.
+func open(config Config, ping bool) (*gorm.DB, error) {
+	dsn, err := config.DSN()
 	if err != nil {
 		return nil, err
 	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  dsn,
+		PreferSimpleProtocol: !config.PrepareStatements,
+	}), &gorm.Config{
+		Logger:               config.Logger,
+		TranslateError:       true,
+		PrepareStmt:          config.PrepareStatements,
+		DisableAutomaticPing: !ping,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	if config.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
 	return db, nil
 }
+
+// Close releases the connection pool behind db.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReplicaOptions control how reads are spread over replicas.
+type ReplicaOptions struct {
+	// HealthInterval is how often each replica is checked
+	HealthInterval time.Duration
+	// MaxLag takes a replica out of rotation when replay falls further behind
+	// the primary than this, zero disables the check
+	MaxLag time.Duration
+	// StickyWindow sends a session's reads to the primary for this long after
+	// it writes, so users see their own changes
+	StickyWindow time.Duration
+}
+
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// Replicas routes reads to healthy read replicas and everything else to the
+// primary. Without replicas all reads go to the primary.
+type Replicas struct {
+	primary  *gorm.DB
+	replicas []*replica
+	options  ReplicaOptions
+	next     atomic.Uint64
+
+	// session key -> time until which its reads stay on the primary
+	writes sync.Map
+}
+
+// NewReplicas connects to each replica URL with the pool settings of base.
+// Replicas that are unreachable start out of rotation instead of failing.
+func NewReplicas(ctx context.Context, primary *gorm.DB, base Config, urls []string, options ReplicaOptions) (*Replicas, error) {
+	r := &Replicas{primary: primary, options: options}
+
+	for _, rawURL := range urls {
+		config := base
+		config.URL = rawURL
+		db, err := open(config, false)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to open replica %s: %w", replicaName(rawURL), err)
+		}
+		r.replicas = append(r.replicas, &replica{name: replicaName(rawURL), db: db})
+	}
+
+	if err := r.trackWrites(primary); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	r.checkHealth(ctx)
+	return r, nil
+}
+
+// trackWrites calls MarkWrite after every create, update and delete on the
+// primary.
+func (r *Replicas) trackWrites(primary *gorm.DB) error {
+	for _, hook := range Hooks(primary) {
+		switch hook.Operation {
+		case "create", "update", "delete":
+			if err := hook.After("replicas:mark_write_"+hook.Operation, r.markWrite); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Replicas) markWrite(db *gorm.DB) {
+	if db.Error == nil && db.Statement.Context != nil {
+		r.MarkWrite(db.Statement.Context)
+	}
+}
+
+// Each calls fn for every replica connection, e.g. to install plugins.
+func (r *Replicas) Each(fn func(name string, db *gorm.DB) error) error {
+	for _, rep := range r.replicas {
+		if err := fn(rep.name, rep.db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reader returns the connection to read from for ctx: the primary while the
+// session has written recently or no replica is healthy, otherwise the next
+// healthy replica in round-robin order.
+func (r *Replicas) Reader(ctx context.Context) *gorm.DB {
+	if len(r.replicas) == 0 {
+		return r.primary
+	}
+
+	if key, ok := SessionKey(ctx); ok {
+		if until, ok := r.writes.Load(key); ok && time.Now().Before(until.(time.Time)) {
+			return r.primary
+		}
+	}
+
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep.db
+		}
+	}
+	return r.primary
+}
+
+// MarkWrite pins the reads of the session in ctx to the primary for the
+// sticky window. Creates, updates and deletes on the primary call it.
+func (r *Replicas) MarkWrite(ctx context.Context) {
+	if len(r.replicas) == 0 || r.options.StickyWindow <= 0 {
+		return
+	}
+	if key, ok := SessionKey(ctx); ok {
+		r.writes.Store(key, time.Now().Add(r.options.StickyWindow))
+	}
+}
+
+// RunHealthChecks checks the replicas every HealthInterval until ctx is done.
+func (r *Replicas) RunHealthChecks(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.options.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkHealth(ctx)
+			r.pruneWrites()
+		}
+	}
+}
+
+// Close releases the replica connection pools.
+func (r *Replicas) Close() error {
+	var firstErr error
+	for _, rep := range r.replicas {
+		if err := Close(rep.db); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (r *Replicas) checkHealth(ctx context.Context) {
+	for _, rep := range r.replicas {
+		err := r.check(ctx, rep)
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy {
+			if healthy {
+				slog.Info("Replica back in rotation", "replica", rep.name)
+			} else {
+				slog.Warn("Replica out of rotation", "replica", rep.name, "error", err)
+			}
+		}
+	}
+}
+
+func (r *Replicas) check(ctx context.Context, rep *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	sqlDB, err := rep.db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return err
+	}
+	if r.options.MaxLag <= 0 {
+		return nil
+	}
+
+	// An idle replica that has replayed everything it received is not lagging,
+	// even though its last replay timestamp keeps getting older
+	var lagSeconds float64
+	err = sqlDB.QueryRowContext(ctx, `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`).Scan(&lagSeconds)
+	if err != nil {
+		return err
+	}
+	if lag := time.Duration(lagSeconds * float64(time.Second)); lag > r.options.MaxLag {
+		return fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Millisecond), r.options.MaxLag)
+	}
+	return nil
+}
+
+func (r *Replicas) pruneWrites() {
+	now := time.Now()
+	r.writes.Range(func(key, until any) bool {
+		if now.After(until.(time.Time)) {
+			r.writes.Delete(key)
+		}
+		return true
+	})
+}
+
+// replicaName identifies a replica in logs without its credentials.
+func replicaName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid-url"
+	}
+	return u.Host + u.Path
+}
+
+type sessionKey struct{}
+
+// WithSession tags ctx with the key used for read-your-writes stickiness,
+// typically the authenticated user.
+func WithSession(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, key)
+}
+
+// SessionKey returns the session key set by WithSession.
+func SessionKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(sessionKey{}).(string)
+	return key, ok && key != ""
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rakibulbanna/go-fiber-postgres/models"
+	"github.com/rakibulbanna/go-fiber-postgres/storage/storagetest"
+	"gorm.io/gorm"
+)
+
+func TestReplicasTrackWrites(t *testing.T) {
+	primary := storagetest.Open(t, &models.User{})
+	replicaDB := storagetest.Open(t)
+
+	r := &Replicas{primary: primary, options: ReplicaOptions{StickyWindow: time.Minute}}
+	rep := &replica{name: "replica", db: replicaDB}
+	rep.healthy.Store(true)
+	r.replicas = append(r.replicas, rep)
+	if err := r.trackWrites(primary); err != nil {
+		t.Fatal(err)
+	}
+
+	user := models.User{Email: "reader@example.com", Password: "x", Name: "Reader"}
+	tests := []struct {
+		name        string
+		run         func(db *gorm.DB) error
+		wantPrimary bool
+	}{
+		{"query", func(db *gorm.DB) error { return db.Find(&[]models.User{}).Error }, false},
+		{"create", func(db *gorm.DB) error { return db.Create(&user).Error }, true},
+		{"update", func(db *gorm.DB) error { return db.Model(&user).Update("name", "Writer").Error }, true},
+		{"delete", func(db *gorm.DB) error { return db.Delete(&user).Error }, true},
+		{"failed write", func(db *gorm.DB) error {
+			db.Create(&models.User{ID: 1, Email: "reader@example.com"})
+			return nil
+		}, false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithSession(context.Background(), fmt.Sprintf("session-%d", i))
+			if err := tt.run(primary.WithContext(ctx)); err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Reader(ctx) == primary; got != tt.wantPrimary {
+				t.Errorf("reads on primary %v, want %v", got, tt.wantPrimary)
+			}
+		})
+	}
+}
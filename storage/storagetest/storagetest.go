@@ -0,0 +1,39 @@
+// Package storagetest opens throwaway databases for tests of the services.
+// They are SQLite rather than Postgres, so the services' Postgres-only SQL,
+// such as ILIKE, is out of their reach, and row locks are no-ops.
+package storagetest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Open returns a database in a temporary directory of t, with tables for
+// the given models. It is closed when t ends.
+func Open(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=1&_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:         gormlogger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+	return db
+}
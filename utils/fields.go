@@ -0,0 +1,22 @@
+package utils
+
+import "encoding/json"
+
+// SelectFields returns the JSON object v encodes to, restricted to the
+// given keys, for sparse fieldsets.
+func SelectFields(v interface{}, keys map[string]bool) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		return nil, err
+	}
+	for key := range object {
+		if !keys[key] {
+			delete(object, key)
+		}
+	}
+	return object, nil
+}
@@ -8,20 +8,46 @@ import (
 )
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID       uint   `json:"user_id"`
+	Email        string `json:"email"`
+	TokenVersion int    `json:"token_version"`
+	// ImpersonatorID is the admin acting as the user, zero for normal logins,
+	// and ImpersonatorTokenVersion the admin's token version at the time
+	ImpersonatorID           uint `json:"impersonator_id,omitempty"`
+	ImpersonatorTokenVersion int  `json:"impersonator_token_version,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, email, secret string) (string, error) {
+// GenerateToken issues a token for the user; tokenVersion must match the
+// user's current version for the token to be accepted.
+func GenerateToken(userID uint, email string, tokenVersion int, secret string) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+		UserID:       userID,
+		Email:        email,
+		TokenVersion: tokenVersion,
+	}
+	return signToken(&claims, time.Now().Add(24*time.Hour), secret)
+}
+
+// GenerateImpersonationToken issues a token letting the admin impersonatorID
+// act as the user until expiresAt, or until either of their sessions is
+// revoked.
+func GenerateImpersonationToken(userID uint, email string, tokenVersion int, impersonatorID uint, impersonatorTokenVersion int, expiresAt time.Time, secret string) (string, error) {
+	claims := Claims{
+		UserID:                   userID,
+		Email:                    email,
+		TokenVersion:             tokenVersion,
+		ImpersonatorID:           impersonatorID,
+		ImpersonatorTokenVersion: impersonatorTokenVersion,
+	}
+	return signToken(&claims, expiresAt, secret)
+}
+
+func signToken(claims *Claims, expiresAt time.Time, secret string) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
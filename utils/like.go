@@ -0,0 +1,11 @@
+package utils
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// LikeContains returns the LIKE pattern matching values that contain s
+// literally, with its wildcards escaped. Use it with ESCAPE '\'.
+func LikeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
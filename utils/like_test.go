@@ -0,0 +1,22 @@
+package utils
+
+import "testing"
+
+func TestLikeContains(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "%%"},
+		{"dune", "%dune%"},
+		{"100%", `%100\%%`},
+		{"snake_case", `%snake\_case%`},
+		{`back\slash`, `%back\\slash%`},
+		{`\%_`, `%\\\%\_%`},
+	}
+
+	for _, tt := range tests {
+		if got := LikeContains(tt.in); got != tt.want {
+			t.Errorf("LikeContains(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}